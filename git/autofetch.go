@@ -0,0 +1,89 @@
+package git
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+)
+
+const (
+	// fetchLockFile is the name of the file, in the Git common directory,
+	// locked by gitmux processes while they check and update fetchStampFile.
+	fetchLockFile = "gitmux-fetch.lock"
+
+	// fetchStampFile is the name of the file, in the Git common directory,
+	// which modification time is the time of the last automatic fetch.
+	fetchStampFile = "gitmux-fetch.stamp"
+)
+
+// AutoFetch starts a background 'git fetch', unless the repository has been
+// fetched less than interval ago. The fetch process is detached from gitmux
+// and never prompts for credentials, so AutoFetch returns immediately and
+// gitmux can exit before the fetch completes.
+func (r *Repo) AutoFetch(interval time.Duration) error {
+	dir, err := r.commonDir()
+	if err != nil {
+		return err
+	}
+
+	last, err := r.LastFetch()
+	if err != nil {
+		return err
+	}
+	if time.Since(last) < interval {
+		return nil
+	}
+
+	ok, err := lockFetch(dir, interval)
+	if err != nil || !ok {
+		return err
+	}
+
+	cmd := exec.Command("git", "fetch", "--quiet")
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0", // never prompt for credentials
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+	)
+	// Once detached, the fetch has no terminal ssh could prompt on either, so
+	// there's no need to override the user's ssh command.
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// lockFetch reports whether the caller should fetch the repository which
+// common directory is dir, that is if no automatic fetch was started less than
+// interval ago, in which case it records the current time as the time of the
+// last automatic fetch. The check and the update happen while holding the
+// fetch lock, so only one of concurrent callers is allowed to fetch.
+func lockFetch(dir string, interval time.Duration) (bool, error) {
+	unlock, err := lockedfile.MutexAt(filepath.Join(dir, fetchLockFile)).Lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	stamp := filepath.Join(dir, fetchStampFile)
+	fi, err := os.Stat(stamp)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return true, os.WriteFile(stamp, nil, 0o644)
+	case err != nil:
+		return false, err
+	case time.Since(fi.ModTime()) < interval:
+		// Another gitmux process is fetching or has fetched recently.
+		return false, nil
+	}
+
+	now := time.Now()
+	return true, os.Chtimes(stamp, now, now)
+}
@@ -0,0 +1,140 @@
+//go:build !windows
+// +build !windows
+
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_lockFetch(t *testing.T) {
+	dir := t.TempDir()
+
+	if ok, err := lockFetch(dir, time.Hour); !ok || err != nil {
+		t.Fatalf("lockFetch() = %t, %v, want true, nil", ok, err)
+	}
+
+	if ok, err := lockFetch(dir, time.Hour); ok || err != nil {
+		t.Fatalf("lockFetch() after recent fetch = %t, %v, want false, nil", ok, err)
+	}
+
+	stamp := filepath.Join(dir, fetchStampFile)
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(stamp, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := lockFetch(dir, time.Hour); !ok || err != nil {
+		t.Fatalf("lockFetch() after old fetch = %t, %v, want true, nil", ok, err)
+	}
+
+	fi, err := os.Stat(stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(fi.ModTime()) > time.Minute {
+		t.Errorf("stamp file not touched, modification time is %v", fi.ModTime())
+	}
+}
+
+func Test_lockFetchConcurrent(t *testing.T) {
+	for _, name := range []string{"no previous fetch", "old fetch"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if name == "old fetch" {
+				stamp := filepath.Join(dir, fetchStampFile)
+				if err := os.WriteFile(stamp, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				old := time.Now().Add(-2 * time.Hour)
+				if err := os.Chtimes(stamp, old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			const n = 20
+			var (
+				wg      sync.WaitGroup
+				fetches atomic.Int32
+			)
+			start := make(chan struct{})
+			for range n {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					ok, err := lockFetch(dir, time.Hour)
+					if err != nil {
+						t.Errorf("lockFetch() error: %v", err)
+					}
+					if ok {
+						fetches.Add(1)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if got := fetches.Load(); got != 1 {
+				t.Errorf("%d concurrent lockFetch() calls returned true, want 1", got)
+			}
+		})
+	}
+}
+
+func TestAutoFetch(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+	other := filepath.Join(tmp, "other")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+
+	// Push a new commit to the remote, from another clone.
+	runGit(t, tmp, "clone", "--branch", "main", remote, other)
+	runGit(t, other, "commit", "--allow-empty", "-m", "Second commit")
+	runGit(t, other, "push", "origin", "main")
+	want := runGit(t, other, "rev-parse", "HEAD")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+	if err := repo.AutoFetch(time.Hour); err != nil {
+		t.Fatalf("AutoFetch error: %v", err)
+	}
+
+	// Wait for the background fetch to complete.
+	deadline := time.Now().Add(10 * time.Second)
+	for runGit(t, local, "rev-parse", "origin/main") != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("origin/main not fetched after 10s")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	stamp := filepath.Join(local, ".git", fetchStampFile)
+	fi, err := os.Stat(stamp)
+	if err != nil {
+		t.Fatalf("stamp file: %v", err)
+	}
+
+	// A second call within the interval doesn't fetch again.
+	if err := repo.AutoFetch(time.Hour); err != nil {
+		t.Fatalf("AutoFetch error: %v", err)
+	}
+	fi2, err := os.Stat(stamp)
+	if err != nil {
+		t.Fatalf("stamp file: %v", err)
+	}
+	if !fi2.ModTime().Equal(fi.ModTime()) {
+		t.Errorf("stamp file modified by second AutoFetch")
+	}
+}
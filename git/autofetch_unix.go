@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in its own session, so that it survives gitmux and
+// isn't attached to the tmux pane terminal.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,12 @@
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run detached from the console of gitmux.
+func detach(cmd *exec.Cmd) {
+	const detachedProcess = 0x00000008
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
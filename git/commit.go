@@ -0,0 +1,39 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit describes a commit.
+type Commit struct {
+	// Time is the committer date.
+	Time time.Time
+
+	// Subject is the first line of the commit message.
+	Subject string
+}
+
+// LastCommit returns the commit at HEAD.
+func (r *Repo) LastCommit() (Commit, error) {
+	out, err := r.run("log", "-1", "--format=%ct %s", "HEAD")
+	if err != nil {
+		return Commit{}, err
+	}
+
+	return parseCommit(strings.TrimRight(out, "\n"))
+}
+
+// parseCommit parses a commit formatted as '%ct %s'.
+func parseCommit(s string) (Commit, error) {
+	ts, subject, _ := strings.Cut(s, " ")
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Commit{}, fmt.Errorf("unexpected commit format %q: %v", s, err)
+	}
+
+	return Commit{Time: time.Unix(sec, 0), Subject: subject}, nil
+}
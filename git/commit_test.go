@@ -0,0 +1,42 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseCommit(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Commit
+		wantErr bool
+	}{
+		{
+			s:    "1700000000 Add some file",
+			want: Commit{Time: time.Unix(1700000000, 0), Subject: "Add some file"},
+		},
+		{
+			s:    "1700000000 ",
+			want: Commit{Time: time.Unix(1700000000, 0)},
+		},
+		{
+			s:    "1700000000",
+			want: Commit{Time: time.Unix(1700000000, 0)},
+		},
+		{
+			s:       "Add some file",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseCommit(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCommit(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !got.Time.Equal(tt.want.Time) || got.Subject != tt.want.Subject {
+				t.Errorf("parseCommit(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Divergence describes how HEAD diverged from another ref.
+type Divergence struct {
+	// Ref is the short name of the ref HEAD is compared with.
+	Ref string
+
+	// Ahead is the number of commits in HEAD that are not in Ref.
+	Ahead int
+
+	// Behind is the number of commits in Ref that are not in HEAD.
+	Behind int
+}
+
+// BaseDivergence returns the divergence of HEAD with the base branch. If base
+// is empty, the base branch is the default branch of the origin remote, as
+// pointed by refs/remotes/origin/HEAD. The returned Divergence is empty if
+// there's no such branch.
+func (r *Repo) BaseDivergence(base string) (Divergence, error) {
+	if base == "" {
+		out, err := r.run("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				// No default branch.
+				return Divergence{}, nil
+			}
+			return Divergence{}, err
+		}
+		base = strings.TrimSpace(out)
+	}
+
+	return r.divergence(base)
+}
+
+// divergence returns the divergence of HEAD with ref.
+func (r *Repo) divergence(ref string) (Divergence, error) {
+	out, err := r.run("rev-list", "--left-right", "--count", ref+"...HEAD")
+	if err != nil {
+		return Divergence{}, err
+	}
+
+	d := Divergence{Ref: ref}
+	if _, err := fmt.Sscanf(out, "%d\t%d", &d.Behind, &d.Ahead); err != nil {
+		return Divergence{}, fmt.Errorf("unexpected rev-list output %q: %v", out, err)
+	}
+	return d, nil
+}
+
+// PushDivergence returns the divergence of HEAD with the branch 'git push'
+// would push to (@{push}), which differs from the upstream branch in
+// triangular workflows. The returned Divergence is empty if there's no push
+// destination or if it doesn't exist yet.
+func (r *Repo) PushDivergence() (Divergence, error) {
+	out, err := r.run("rev-parse", "--abbrev-ref", "@{push}")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// No push destination.
+			return Divergence{}, nil
+		}
+		return Divergence{}, err
+	}
+
+	return r.divergence(strings.TrimSpace(out))
+}
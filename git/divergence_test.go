@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseDivergence(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "checkout", "-b", "feature")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Feature commit")
+	runGit(t, local, "checkout", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Main commit 1")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Main commit 2")
+	runGit(t, local, "push", "origin", "main")
+	runGit(t, local, "checkout", "feature")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	// No refs/remotes/origin/HEAD.
+	d, err := repo.BaseDivergence("")
+	if err != nil {
+		t.Fatalf("BaseDivergence error: %v", err)
+	}
+	if d != (Divergence{}) {
+		t.Errorf("BaseDivergence() = %+v, want empty", d)
+	}
+
+	runGit(t, local, "remote", "set-head", "origin", "main")
+	d, err = repo.BaseDivergence("")
+	if err != nil {
+		t.Fatalf("BaseDivergence error: %v", err)
+	}
+	if want := (Divergence{Ref: "origin/main", Ahead: 1, Behind: 2}); d != want {
+		t.Errorf("BaseDivergence() = %+v, want %+v", d, want)
+	}
+
+	d, err = repo.BaseDivergence("main")
+	if err != nil {
+		t.Fatalf("BaseDivergence error: %v", err)
+	}
+	if want := (Divergence{Ref: "main", Ahead: 1, Behind: 2}); d != want {
+		t.Errorf("BaseDivergence(main) = %+v, want %+v", d, want)
+	}
+}
+
+func TestPushDivergence(t *testing.T) {
+	tmp := t.TempDir()
+	upstream := filepath.Join(tmp, "upstream.git")
+	fork := filepath.Join(tmp, "fork.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", upstream)
+	runGit(t, tmp, "init", "--bare", fork)
+	runGit(t, tmp, "clone", upstream, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	// Push and upstream are the same.
+	d, err := repo.PushDivergence()
+	if err != nil {
+		t.Fatalf("PushDivergence error: %v", err)
+	}
+	if want := (Divergence{Ref: "origin/main"}); d != want {
+		t.Errorf("PushDivergence() = %+v, want %+v", d, want)
+	}
+
+	// Triangular workflow: track origin/main, push to fork/feature.
+	runGit(t, local, "remote", "add", "fork", fork)
+	runGit(t, local, "config", "remote.pushDefault", "fork")
+	runGit(t, local, "config", "push.default", "current")
+	runGit(t, local, "checkout", "-b", "feature", "--track", "origin/main")
+
+	// Push branch doesn't exist yet.
+	d, err = repo.PushDivergence()
+	if err != nil {
+		t.Fatalf("PushDivergence error: %v", err)
+	}
+	if d != (Divergence{}) {
+		t.Errorf("PushDivergence() = %+v, want empty", d)
+	}
+
+	runGit(t, local, "commit", "--allow-empty", "-m", "Feature commit 1")
+	runGit(t, local, "push")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Feature commit 2")
+
+	d, err = repo.PushDivergence()
+	if err != nil {
+		t.Fatalf("PushDivergence error: %v", err)
+	}
+	if want := (Divergence{Ref: "fork/feature", Ahead: 1}); d != want {
+		t.Errorf("PushDivergence() = %+v, want %+v", d, want)
+	}
+}
@@ -0,0 +1,29 @@
+package git
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// LastFetch returns the time of the last fetch, that is the modification time
+// of FETCH_HEAD. Each worktree has its own FETCH_HEAD, so it's the time of the
+// last fetch from the current worktree. The returned time is zero if it has
+// never been fetched.
+func (r *Repo) LastFetch() (time.Time, error) {
+	out, err := r.run("rev-parse", "--git-path", "FETCH_HEAD")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	fi, err := os.Stat(strings.TrimSpace(out))
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestLastFetch(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+	linked := filepath.Join(tmp, "linked")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "worktree", "add", "-b", "feat", linked)
+
+	lastFetch := func(dir string) bool {
+		t.Helper()
+		t.Chdir(dir)
+		last, err := New(context.Background()).LastFetch()
+		if err != nil {
+			t.Fatalf("LastFetch() in %s error: %v", dir, err)
+		}
+		return !last.IsZero()
+	}
+
+	if lastFetch(local) || lastFetch(linked) {
+		t.Fatalf("LastFetch() is not zero before fetching")
+	}
+
+	// Fetching from the linked worktree writes its own FETCH_HEAD.
+	runGit(t, linked, "fetch")
+	if !lastFetch(linked) {
+		t.Errorf("LastFetch() in linked worktree is zero after fetching")
+	}
+	if lastFetch(local) {
+		t.Errorf("LastFetch() in main worktree is not zero after fetching from the linked worktree")
+	}
+
+	runGit(t, local, "fetch")
+	if !lastFetch(local) {
+		t.Errorf("LastFetch() in main worktree is zero after fetching")
+	}
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// InsideGitDir reports whether the current directory is inside a Git
+// directory, which is always the case in a bare repository, where there's no
+// working tree.
+func (r *Repo) InsideGitDir() (inside, bare bool, err error) {
+	out, err := r.run("rev-parse", "--is-inside-git-dir", "--is-bare-repository")
+	if err != nil {
+		return false, false, err
+	}
+
+	lines := strings.Fields(out)
+	if len(lines) != 2 {
+		return false, false, fmt.Errorf("unexpected rev-parse output %q", out)
+	}
+	return lines[0] == "true", lines[1] == "true", nil
+}
+
+// Head describes HEAD.
+type Head struct {
+	// Branch is the name of the current branch, or empty if HEAD is detached.
+	Branch string
+
+	// Hash is the shortened hash of the current commit, or empty if there's
+	// no commit yet.
+	Hash string
+}
+
+// Head returns the current branch and commit. Contrary to gitstatus, it also
+// works without a working tree.
+func (r *Repo) Head() (Head, error) {
+	var (
+		head    Head
+		exitErr *exec.ExitError
+	)
+
+	out, err := r.run("symbolic-ref", "--quiet", "--short", "HEAD")
+	switch {
+	case errors.As(err, &exitErr):
+		// Detached HEAD.
+	case err != nil:
+		return Head{}, err
+	default:
+		head.Branch = strings.TrimSpace(out)
+	}
+
+	out, err = r.run("rev-parse", "--verify", "--quiet", "--short", "HEAD")
+	switch {
+	case errors.As(err, &exitErr):
+		// No commit yet.
+	case err != nil:
+		return Head{}, err
+	default:
+		head.Hash = strings.TrimSpace(out)
+	}
+
+	return head, nil
+}
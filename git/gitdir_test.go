@@ -0,0 +1,84 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsideGitDir(t *testing.T) {
+	tmp := t.TempDir()
+	bare := filepath.Join(tmp, "bare.git")
+	repo := filepath.Join(tmp, "repo")
+
+	runGit(t, tmp, "init", "--bare", bare)
+	runGit(t, tmp, "init", repo)
+
+	tests := []struct {
+		name       string
+		dir        string
+		wantInside bool
+		wantBare   bool
+	}{
+		{name: "working tree", dir: repo},
+		{name: "git dir", dir: filepath.Join(repo, ".git", "refs"), wantInside: true},
+		{name: "bare", dir: bare, wantInside: true, wantBare: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(tt.dir)
+
+			inside, bare, err := New(context.Background()).InsideGitDir()
+			if err != nil {
+				t.Fatalf("InsideGitDir() error: %v", err)
+			}
+			if inside != tt.wantInside || bare != tt.wantBare {
+				t.Errorf("InsideGitDir() = %t, %t, want %t, %t", inside, bare, tt.wantInside, tt.wantBare)
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	tmp := t.TempDir()
+	bare := filepath.Join(tmp, "bare.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", bare)
+	runGit(t, bare, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	t.Chdir(bare)
+	repo := New(context.Background())
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Head() error: %v", err)
+	}
+	if want := (Head{Branch: "main"}); head != want {
+		t.Errorf("Head() = %+v, want %+v", head, want)
+	}
+
+	runGit(t, tmp, "clone", bare, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "origin", "main")
+	hash := runGit(t, bare, "rev-parse", "--short", "HEAD")
+
+	head, err = repo.Head()
+	if err != nil {
+		t.Fatalf("Head() error: %v", err)
+	}
+	if want := (Head{Branch: "main", Hash: hash}); head != want {
+		t.Errorf("Head() = %+v, want %+v", head, want)
+	}
+
+	runGit(t, bare, "update-ref", "--no-deref", "HEAD", "HEAD")
+
+	head, err = repo.Head()
+	if err != nil {
+		t.Fatalf("Head() error: %v", err)
+	}
+	if want := (Head{Hash: hash}); head != want {
+		t.Errorf("Head() = %+v, want %+v", head, want)
+	}
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Identity describes the identity commits are created with.
+type Identity struct {
+	// Email is the user.email setting.
+	Email string
+
+	// NoSigningKey reports whether commits are signed, as set by
+	// commit.gpgsign, while no signing key is set with user.signingkey.
+	NoSigningKey bool
+}
+
+// Identity returns the identity commits are created with in the repository,
+// which takes into account the repository, global and system configurations.
+func (r *Repo) Identity() (Identity, error) {
+	out, err := r.run("config", "--get-regexp", `^(user\.email|user\.signingkey|commit\.gpgsign)$`)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			// None of these is set.
+			return Identity{}, nil
+		}
+		return Identity{}, err
+	}
+
+	return parseIdentityConfig(out), nil
+}
+
+// parseIdentityConfig parses the output of 'git config --get-regexp', which
+// keys are lowercase. When a key is set several times, the last value wins.
+func parseIdentityConfig(out string) Identity {
+	var (
+		id         Identity
+		gpgSign    bool
+		signingKey string
+	)
+
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		key, val, found := strings.Cut(scan.Text(), " ")
+		switch key {
+		case "user.email":
+			id.Email = val
+		case "user.signingkey":
+			signingKey = val
+		case "commit.gpgsign":
+			gpgSign = isTrue(val, found)
+		}
+	}
+
+	id.NoSigningKey = gpgSign && signingKey == ""
+	return id
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func Test_parseIdentityConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want Identity
+	}{
+		{
+			name: "empty",
+			out:  "",
+		},
+		{
+			name: "email",
+			out:  "user.email me@example.com\n",
+			want: Identity{Email: "me@example.com"},
+		},
+		{
+			name: "last email wins",
+			out:  "user.email me@example.com\nuser.email me@work.com\n",
+			want: Identity{Email: "me@work.com"},
+		},
+		{
+			name: "signing without key",
+			out:  "user.email me@example.com\ncommit.gpgsign true\n",
+			want: Identity{Email: "me@example.com", NoSigningKey: true},
+		},
+		{
+			name: "signing without value",
+			out:  "commit.gpgsign\n",
+			want: Identity{NoSigningKey: true},
+		},
+		{
+			name: "signing with empty value",
+			out:  "commit.gpgsign \n",
+			want: Identity{},
+		},
+		{
+			name: "signing with key",
+			out:  "user.signingkey 3AA5C34371567BD2\ncommit.gpgsign yes\n",
+			want: Identity{},
+		},
+		{
+			name: "signing disabled",
+			out:  "commit.gpgsign true\ncommit.gpgsign false\n",
+			want: Identity{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseIdentityConfig(tt.out); got != tt.want {
+				t.Errorf("parseIdentityConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	// Ignore the user configuration.
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+
+	t.Chdir(dir)
+	repo := New(context.Background())
+
+	id, err := repo.Identity()
+	if err != nil {
+		t.Fatalf("Identity() error: %v", err)
+	}
+	if id != (Identity{}) {
+		t.Errorf("Identity() = %+v, want empty", id)
+	}
+
+	runGit(t, dir, "config", "user.email", "me@work.com")
+	runGit(t, dir, "config", "commit.gpgsign", "true")
+
+	id, err = repo.Identity()
+	if err != nil {
+		t.Fatalf("Identity() error: %v", err)
+	}
+	want := Identity{Email: "me@work.com", NoSigningKey: true}
+	if id != want {
+		t.Errorf("Identity() = %+v, want %+v", id, want)
+	}
+}
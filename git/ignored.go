@@ -0,0 +1,88 @@
+package git
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Ignored describes the ignored files of the working tree.
+type Ignored struct {
+	// Count is the number of ignored paths. An ignored directory counts as a
+	// single path.
+	Count int
+
+	// Size is the total size in bytes of the ignored files. It is only
+	// computed on demand.
+	Size int64
+}
+
+// Ignored returns the ignored paths of the working tree. The total size of the
+// ignored files is only computed if size is true, since it requires walking
+// every ignored directory.
+func (r *Repo) Ignored(size bool) (Ignored, error) {
+	out, err := r.run("status", "--porcelain=v2", "-z", "--ignored=matching", "--untracked-files=normal")
+	if err != nil {
+		return Ignored{}, err
+	}
+
+	entries, err := parsePorcelainV2(out)
+	if err != nil {
+		return Ignored{}, err
+	}
+
+	var (
+		ign   Ignored
+		paths []string
+	)
+	for _, e := range entries {
+		if e.kind == ignoredEntry {
+			ign.Count++
+			paths = append(paths, e.path)
+		}
+	}
+	if !size || len(paths) == 0 {
+		return ign, nil
+	}
+
+	// Porcelain paths are relative to the top-level directory.
+	out, err = r.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return Ignored{}, err
+	}
+	top := strings.TrimSpace(out)
+
+	for _, path := range paths {
+		n, err := r.diskUsage(filepath.Join(top, path))
+		if err != nil {
+			return Ignored{}, err
+		}
+		ign.Size += n
+	}
+	return ign, nil
+}
+
+// diskUsage returns the total size in bytes of the regular files under root.
+// Symbolic links are not followed. Walking stops as soon as the Repo context
+// is done.
+func (r *Repo) diskUsage(root string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(".gitignore", "build/\n*.o\n")
+	writeFile("build/bin/app", strings.Repeat("x", 100))
+	writeFile("build/app.log", strings.Repeat("x", 20))
+	writeFile("src/main.o", strings.Repeat("x", 3))
+	writeFile("src/main.c", strings.Repeat("x", 1000))
+
+	t.Chdir(filepath.Join(dir, "src"))
+	repo := New(context.Background())
+
+	tests := []struct {
+		name string
+		size bool
+		want Ignored
+	}{
+		{name: "count only", size: false, want: Ignored{Count: 2}},
+		{name: "with size", size: true, want: Ignored{Count: 2, Size: 123}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Ignored(tt.size)
+			if err != nil {
+				t.Fatalf("Ignored() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Ignored() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoredCanceled(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+
+	t.Chdir(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := New(ctx).Ignored(true); err == nil {
+		t.Errorf("Ignored() with a canceled context should return an error")
+	}
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// LFS describes the Git LFS files of the repository.
+type LFS struct {
+	// Pointers is the number of LFS files which are pointers in the working
+	// tree, because their content has not been downloaded (smudged).
+	Pointers int
+
+	// Unpushed is the number of LFS objects to push to the upstream branch.
+	Unpushed int
+
+	// Locks is the number of files locked by the current user.
+	Locks int
+}
+
+// LFS returns the state of the Git LFS files. The returned LFS is empty if the
+// repository doesn't use LFS, that is if it has no lfs directory in its Git
+// directory, or if git-lfs is not installed.
+func (r *Repo) LFS() (LFS, error) {
+	dir, err := r.commonDir()
+	if err != nil {
+		return LFS{}, err
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "lfs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return LFS{}, nil
+	}
+	if err != nil {
+		return LFS{}, err
+	}
+
+	out, err := r.run("lfs", "ls-files")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "is not a git command") {
+			// git-lfs is not installed.
+			return LFS{}, nil
+		}
+		return LFS{}, err
+	}
+	lfs := LFS{Pointers: parseLFSFiles(out)}
+
+	out, err = r.run("lfs", "status")
+	if err != nil {
+		return LFS{}, err
+	}
+	lfs.Unpushed = parseLFSStatus(out)
+
+	// Only list the locks cached locally, which are the ones of the current
+	// user, since asking the LFS server would be too slow.
+	out, err = r.run("lfs", "locks", "--local", "--json")
+	if err != nil {
+		return LFS{}, err
+	}
+	lfs.Locks, err = parseLFSLocks(out)
+	return lfs, err
+}
+
+// parseLFSFiles returns the number of pointer files in the output of 'git lfs
+// ls-files', where each line has the form <oid> <*|-> <path>, - meaning that
+// the file is a pointer.
+func parseLFSFiles(out string) int {
+	n := 0
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) >= 3 && fields[1] == "-" {
+			n++
+		}
+	}
+	return n
+}
+
+// parseLFSStatus returns the number of objects listed in the 'Objects to be
+// pushed' section of the output of 'git lfs status', which has one
+// tab-indented line per object.
+func parseLFSStatus(out string) int {
+	n := 0
+	inPush := false
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		line := scan.Text()
+		switch {
+		case strings.HasPrefix(line, "Objects to be pushed to "):
+			inPush = true
+		case strings.HasPrefix(line, "\t"):
+			if inPush {
+				n++
+			}
+		case line != "":
+			inPush = false
+		}
+	}
+	return n
+}
+
+// parseLFSLocks returns the number of locks in the output of 'git lfs locks
+// --json', which is a JSON array.
+func parseLFSLocks(out string) (int, error) {
+	var locks []json.RawMessage
+	if err := json.Unmarshal([]byte(out), &locks); err != nil {
+		return 0, fmt.Errorf("unexpected lfs locks output %q: %v", out, err)
+	}
+	return len(locks), nil
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseLFSFiles(t *testing.T) {
+	out := "4d7a214614 * assets/logo.png\n" +
+		"3b18e512db - assets/video.mp4\n" +
+		"e3b0c44298 - assets/with space.bin\n"
+
+	if got := parseLFSFiles(out); got != 2 {
+		t.Errorf("parseLFSFiles() = %d, want 2", got)
+	}
+}
+
+func Test_parseLFSStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want int
+	}{
+		{
+			name: "nothing to push",
+			out: "On branch main\n" +
+				"Objects to be pushed to origin/main:\n\n\n" +
+				"Objects to be committed:\n\n" +
+				"\tassets/logo.png (LFS: 4d7a214)\n\n" +
+				"Objects not staged for commit:\n\n\n",
+			want: 0,
+		},
+		{
+			name: "objects to push",
+			out: "On branch main\n" +
+				"Objects to be pushed to origin/main:\n\n" +
+				"\tassets/logo.png (4d7a214614)\n" +
+				"\tassets/video.mp4 (3b18e512db)\n\n" +
+				"Objects to be committed:\n\n\n" +
+				"Objects not staged for commit:\n\n" +
+				"\tassets/logo.png (LFS: 4d7a214 -> File: 9a0364b)\n\n",
+			want: 2,
+		},
+		{
+			name: "no upstream",
+			out: "On branch main\n" +
+				"Objects to be committed:\n\n" +
+				"\tassets/logo.png (LFS: 4d7a214)\n\n" +
+				"Objects not staged for commit:\n\n\n",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLFSStatus(tt.out); got != tt.want {
+				t.Errorf("parseLFSStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseLFSLocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "no locks",
+			out:  "[]\n",
+			want: 0,
+		},
+		{
+			name: "locks",
+			out:  `[{"id":"1","path":"a.psd","owner":{"name":"arl"},"locked_at":"2024-01-02T15:04:05Z"},{"id":"2","path":"b.psd","owner":{"name":"arl"},"locked_at":"2024-01-02T15:04:05Z"}]`,
+			want: 2,
+		},
+		{
+			name:    "invalid",
+			out:     "a.psd\tarl\tID:1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLFSLocks(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLFSLocks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseLFSLocks() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLFSNotUsed(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Initial commit")
+
+	t.Chdir(dir)
+	lfs, err := New(context.Background()).LFS()
+	if err != nil {
+		t.Fatalf("LFS() error: %v", err)
+	}
+	if lfs != (LFS{}) {
+		t.Errorf("LFS() = %+v, want empty", lfs)
+	}
+}
+
+func TestLFSNotInstalled(t *testing.T) {
+	if exec.Command("git", "lfs", "version").Run() == nil {
+		t.Skip("git-lfs is installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Initial commit")
+	if err := os.Mkdir(filepath.Join(dir, ".git", "lfs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(dir)
+	lfs, err := New(context.Background()).LFS()
+	if err != nil {
+		t.Fatalf("LFS() error: %v", err)
+	}
+	if lfs != (LFS{}) {
+		t.Errorf("LFS() = %+v, want empty", lfs)
+	}
+}
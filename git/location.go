@@ -0,0 +1,50 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Location describes where the current directory is in the repository.
+type Location struct {
+	// TopLevel is the absolute path of the top-level directory of the
+	// working tree.
+	TopLevel string
+
+	// Prefix is the path of the current directory relative to TopLevel,
+	// with a trailing slash, or empty at the top-level directory.
+	Prefix string
+}
+
+// Location returns the location of the current directory in the repository.
+func (r *Repo) Location() (Location, error) {
+	out, err := r.run("rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return Location{}, err
+	}
+
+	// --show-prefix prints an empty line at the top-level directory.
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		return Location{}, fmt.Errorf("unexpected rev-parse output %q", out)
+	}
+	return Location{TopLevel: lines[0], Prefix: lines[1]}, nil
+}
+
+// RemoteURL returns the URL of the remote of the current branch, or of origin
+// if the branch has no upstream. The returned URL is empty if there's no such
+// remote.
+func (r *Repo) RemoteURL() (string, error) {
+	out, err := r.run("ls-remote", "--get-url")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// No remote configured.
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
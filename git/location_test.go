@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocation(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	top := runGit(t, dir, "rev-parse", "--show-toplevel")
+
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want Location
+	}{
+		{dir: dir, want: Location{TopLevel: top}},
+		{dir: sub, want: Location{TopLevel: top, Prefix: "a/b/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.Prefix, func(t *testing.T) {
+			t.Chdir(tt.dir)
+
+			got, err := New(context.Background()).Location()
+			if err != nil {
+				t.Fatalf("Location() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Location() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+
+	t.Chdir(dir)
+	repo := New(context.Background())
+
+	url, err := repo.RemoteURL()
+	if err != nil {
+		t.Fatalf("RemoteURL() error: %v", err)
+	}
+	if url != "" {
+		t.Errorf("RemoteURL() = %q, want no remote URL", url)
+	}
+
+	runGit(t, dir, "remote", "add", "origin", "https://github.com/arl/gitmux.git")
+
+	url, err = repo.RemoteURL()
+	if err != nil {
+		t.Fatalf("RemoteURL() error: %v", err)
+	}
+	if want := "https://github.com/arl/gitmux.git"; url != want {
+		t.Errorf("RemoteURL() = %q, want %q", url, want)
+	}
+}
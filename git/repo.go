@@ -0,0 +1,65 @@
+// Package git retrieves information about a Git repository that is not part
+// of gitstatus.Status.
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Repo runs Git commands in the current working directory.
+type Repo struct {
+	ctx context.Context
+}
+
+// New returns a Repo for the current working directory.
+//
+// The provided context is used to stop running git commands once it becomes
+// done, in which case Repo methods return an error.
+func New(ctx context.Context) *Repo {
+	return &Repo{ctx: ctx}
+}
+
+// run runs git with the given arguments and returns its standard output.
+func (r *Repo) run(args ...string) (string, error) {
+	if err := r.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(r.ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		"LC_ALL=C",             // override any user-specific localization
+		"GIT_OPTIONAL_LOCKS=0", // disable operations requiring locks
+	)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("exec %s '%v': %w", cmd.Path, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// commonDir returns the path of the directory shared by all the worktrees of
+// the repository, where refs and objects are stored.
+func (r *Repo) commonDir() (string, error) {
+	out, err := r.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// IsRepo reports whether the current directory is in a Git repository. It
+// only returns an error if Git fails for another reason.
+func (r *Repo) IsRepo() (bool, error) {
+	_, err := r.run("rev-parse", "--git-dir")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "not a git repository") {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGit runs git in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Testeur DeTest",
+		"GIT_AUTHOR_EMAIL=tester@email.com",
+		"GIT_COMMITTER_NAME=Testeur DeTest",
+		"GIT_COMMITTER_EMAIL=tester@email.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestIsRepo(t *testing.T) {
+	dir := t.TempDir()
+	// Don't look for a repository above dir.
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	t.Chdir(dir)
+
+	repo := New(context.Background())
+
+	ok, err := repo.IsRepo()
+	if err != nil {
+		t.Fatalf("IsRepo() error: %v", err)
+	}
+	if ok {
+		t.Errorf("IsRepo() = true outside of a repository")
+	}
+
+	runGit(t, dir, "init")
+
+	ok, err = repo.IsRepo()
+	if err != nil {
+		t.Fatalf("IsRepo() error: %v", err)
+	}
+	if !ok {
+		t.Errorf("IsRepo() = false in a repository")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(ctx).IsRepo(); err == nil {
+		t.Errorf("IsRepo() with a canceled context should return an error")
+	}
+}
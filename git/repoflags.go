@@ -0,0 +1,78 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// RepoFlags describes repository settings which make the history or the
+// working tree incomplete.
+type RepoFlags struct {
+	// Shallow reports whether the repository is a shallow clone, with a
+	// truncated history.
+	Shallow bool
+
+	// Partial reports whether the repository is a partial clone, which
+	// objects are fetched on demand from a promisor remote.
+	Partial bool
+
+	// Sparse reports whether sparse checkout is enabled, so that only a
+	// subset of the files are in the working tree.
+	Sparse bool
+}
+
+// RepoFlags returns the repository flags.
+func (r *Repo) RepoFlags() (RepoFlags, error) {
+	out, err := r.run("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return RepoFlags{}, err
+	}
+	flags := RepoFlags{Shallow: strings.TrimSpace(out) == "true"}
+
+	out, err = r.run("config", "--get-regexp", `^(core\.sparsecheckout|extensions\.partialclone|remote\..*\.promisor)$`)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			// None of these is set.
+			return flags, nil
+		}
+		return RepoFlags{}, err
+	}
+
+	parseRepoFlagsConfig(&flags, out)
+	return flags, nil
+}
+
+// parseRepoFlagsConfig sets the partial and sparse flags from the output of
+// 'git config --get-regexp', which keys are lowercase.
+func parseRepoFlagsConfig(flags *RepoFlags, out string) {
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		key, val, found := strings.Cut(scan.Text(), " ")
+		switch {
+		case key == "core.sparsecheckout":
+			flags.Sparse = flags.Sparse || isTrue(val, found)
+		case key == "extensions.partialclone":
+			// The value is the name of the promisor remote.
+			flags.Partial = flags.Partial || val != ""
+		case strings.HasPrefix(key, "remote.") && strings.HasSuffix(key, ".promisor"):
+			flags.Partial = flags.Partial || isTrue(val, found)
+		}
+	}
+}
+
+// isTrue reports whether val is a true boolean config value, found reporting
+// whether 'git config --get-regexp' printed a value after the key. A boolean
+// key without value is true, while a key set to an empty value is false.
+func isTrue(val string, found bool) bool {
+	if !found {
+		return true
+	}
+	switch strings.ToLower(val) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
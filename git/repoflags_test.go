@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseRepoFlagsConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want RepoFlags
+	}{
+		{
+			name: "empty",
+			out:  "",
+		},
+		{
+			name: "sparse",
+			out:  "core.sparsecheckout true\n",
+			want: RepoFlags{Sparse: true},
+		},
+		{
+			name: "sparse without value",
+			out:  "core.sparsecheckout\n",
+			want: RepoFlags{Sparse: true},
+		},
+		{
+			name: "sparse with empty value",
+			out:  "core.sparsecheckout \n",
+		},
+		{
+			name: "sparse disabled",
+			out:  "core.sparsecheckout false\n",
+		},
+		{
+			name: "promisor remote",
+			out:  "remote.origin.promisor true\n",
+			want: RepoFlags{Partial: true},
+		},
+		{
+			name: "partial clone extension",
+			out:  "extensions.partialclone origin\n",
+			want: RepoFlags{Partial: true},
+		},
+		{
+			name: "all",
+			out:  "core.sparsecheckout yes\nremote.upstream.promisor false\nremote.origin.promisor on\n",
+			want: RepoFlags{Partial: true, Sparse: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RepoFlags
+			parseRepoFlagsConfig(&got, tt.out)
+			if got != tt.want {
+				t.Errorf("parseRepoFlagsConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoFlags(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	shallow := filepath.Join(tmp, "shallow")
+	sparse := filepath.Join(tmp, "sparse")
+
+	runGit(t, tmp, "init", src)
+	runGit(t, src, "commit", "--allow-empty", "-m", "First commit")
+	runGit(t, src, "commit", "--allow-empty", "-m", "Second commit")
+	runGit(t, tmp, "clone", "--depth", "1", "file://"+filepath.ToSlash(src), shallow)
+	runGit(t, tmp, "clone", src, sparse)
+	runGit(t, sparse, "sparse-checkout", "set", "dir")
+
+	tests := []struct {
+		name string
+		dir  string
+		want RepoFlags
+	}{
+		{name: "regular", dir: src},
+		{name: "shallow", dir: shallow, want: RepoFlags{Shallow: true}},
+		{name: "sparse", dir: sparse, want: RepoFlags{Sparse: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(tt.dir)
+
+			got, err := New(context.Background()).RepoFlags()
+			if err != nil {
+				t.Fatalf("RepoFlags() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RepoFlags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stash describes a stash entry.
+type Stash struct {
+	// Time is the time the stash entry was created.
+	Time time.Time
+
+	// Branch is the branch the stash entry was created on. It's empty if HEAD
+	// was detached.
+	Branch string
+}
+
+// Stashes returns the stash entries, from the newest to the oldest.
+func (r *Repo) Stashes() ([]Stash, error) {
+	out, err := r.run("stash", "list", "--format=%ct %gs")
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		st, err := parseStash(scan.Text())
+		if err != nil {
+			return nil, err
+		}
+		stashes = append(stashes, st)
+	}
+	return stashes, nil
+}
+
+// parseStash parses a stash entry formatted as '%ct %gs', where the reflog
+// subject is 'WIP on <branch>: <commit>' for 'git stash', or 'On <branch>:
+// <message>' when a message is given.
+func parseStash(s string) (Stash, error) {
+	ts, subject, _ := strings.Cut(s, " ")
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Stash{}, fmt.Errorf("unexpected stash format %q: %v", s, err)
+	}
+	st := Stash{Time: time.Unix(sec, 0)}
+
+	for _, prefix := range []string{"WIP on ", "On "} {
+		if rest, ok := strings.CutPrefix(subject, prefix); ok {
+			if branch, _, ok := strings.Cut(rest, ": "); ok && branch != "(no branch)" {
+				st.Branch = branch
+			}
+			break
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_parseStash(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Stash
+		wantErr bool
+	}{
+		{
+			s:    "1700000000 WIP on main: 245de57 Add some file",
+			want: Stash{Time: time.Unix(1700000000, 0), Branch: "main"},
+		},
+		{
+			s:    "1700000000 On feat/x: try something",
+			want: Stash{Time: time.Unix(1700000000, 0), Branch: "feat/x"},
+		},
+		{
+			s:    "1700000000 WIP on (no branch): 245de57 Add some file",
+			want: Stash{Time: time.Unix(1700000000, 0)},
+		},
+		{
+			s:    "1700000000 autostash",
+			want: Stash{Time: time.Unix(1700000000, 0)},
+		},
+		{
+			s:       "WIP on main: 245de57 Add some file",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseStash(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStash(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !got.Time.Equal(tt.want.Time) || got.Branch != tt.want.Branch {
+				t.Errorf("parseStash(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStashes(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "file.txt")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+
+	t.Chdir(dir)
+	repo := New(context.Background())
+
+	stashes, err := repo.Stashes()
+	if err != nil {
+		t.Fatalf("Stashes() error: %v", err)
+	}
+	if len(stashes) != 0 {
+		t.Errorf("Stashes() = %+v, want none", stashes)
+	}
+
+	stash := func(content string, args ...string) {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, append([]string{"stash"}, args...)...)
+	}
+	stash("second\n")
+	runGit(t, dir, "checkout", "-b", "feat")
+	stash("third\n", "push", "-m", "some message")
+
+	stashes, err = repo.Stashes()
+	if err != nil {
+		t.Fatalf("Stashes() error: %v", err)
+	}
+	if len(stashes) != 2 || stashes[0].Branch != "feat" || stashes[1].Branch != "main" {
+		t.Errorf("Stashes() = %+v, want stashes on feat and main", stashes)
+	}
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stats holds the number of changed lines.
+type Stats struct {
+	// Insertions is the number of inserted lines.
+	Insertions int
+
+	// Deletions is the number of deleted lines.
+	Deletions int
+}
+
+// Stats returns the number of lines changed in the staging area if staged is
+// true, or in the working tree otherwise. Binary files are not counted.
+func (r *Repo) Stats(staged bool) (Stats, error) {
+	args := []string{"diff", "--numstat"}
+	if staged {
+		args = append(args, "--cached")
+	}
+
+	out, err := r.run(args...)
+	if err != nil {
+		return Stats{}, err
+	}
+	return parseNumstat(out)
+}
+
+// parseNumstat parses the output of 'git diff --numstat'.
+func parseNumstat(out string) (Stats, error) {
+	var st Stats
+
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		fields := strings.SplitN(scan.Text(), "\t", 3)
+		if len(fields) != 3 {
+			return Stats{}, fmt.Errorf("unexpected numstat line %q", scan.Text())
+		}
+		if fields[0] == "-" && fields[1] == "-" {
+			// Binary file.
+			continue
+		}
+
+		ins, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return Stats{}, fmt.Errorf("unexpected numstat line %q: %v", scan.Text(), err)
+		}
+		del, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Stats{}, fmt.Errorf("unexpected numstat line %q: %v", scan.Text(), err)
+		}
+		st.Insertions += ins
+		st.Deletions += del
+	}
+
+	return st, scan.Err()
+}
@@ -0,0 +1,53 @@
+package git
+
+import "testing"
+
+func Test_parseNumstat(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    Stats
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			out:  "",
+		},
+		{
+			name: "files",
+			out:  "12\t3\tsome_file\n0\t7\tsome other file\n",
+			want: Stats{Insertions: 12, Deletions: 10},
+		},
+		{
+			name: "binary file",
+			out:  "-\t-\timage.png\n1\t1\tsome_file\n",
+			want: Stats{Insertions: 1, Deletions: 1},
+		},
+		{
+			name: "renamed file",
+			out:  "2\t0\told => new\n",
+			want: Stats{Insertions: 2},
+		},
+		{
+			name:    "unexpected line",
+			out:     "12 3 some_file\n",
+			wantErr: true,
+		},
+		{
+			name:    "unexpected count",
+			out:     "a\t3\tsome_file\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNumstat(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNumstat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseNumstat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
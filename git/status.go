@@ -0,0 +1,322 @@
+package git
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arl/gitstatus"
+)
+
+// Changes holds the number of changed files, by kind of change, either in the
+// index or in the working tree.
+type Changes struct {
+	// Added is the number of added files.
+	Added int
+
+	// Deleted is the number of deleted files.
+	Deleted int
+
+	// Renamed is the number of renamed or copied files.
+	Renamed int
+
+	// TypeChanged is the number of files which type changed, for example a
+	// regular file replaced with a symbolic link.
+	TypeChanged int
+}
+
+// Changes returns the number of changed files by kind of change.
+func (r *Repo) Changes() (Changes, error) {
+	out, err := r.run("status", "--porcelain=v2", "-z", "--untracked-files=no")
+	if err != nil {
+		return Changes{}, err
+	}
+
+	entries, err := parsePorcelainV2(out)
+	if err != nil {
+		return Changes{}, err
+	}
+	return countChanges(entries), nil
+}
+
+// countChanges counts the changed files in entries, by kind of change.
+func countChanges(entries []entry) Changes {
+	var c Changes
+	for _, e := range entries {
+		if e.kind != changedEntry && e.kind != renamedEntry {
+			continue
+		}
+		x, y := e.xy[0], e.xy[1]
+		if x == 'A' || y == 'A' {
+			c.Added++
+		}
+		if x == 'D' || y == 'D' {
+			c.Deleted++
+		}
+		if x == 'R' || x == 'C' || y == 'R' || y == 'C' {
+			c.Renamed++
+		}
+		if x == 'T' || y == 'T' {
+			c.TypeChanged++
+		}
+	}
+	return c
+}
+
+// Kinds of porcelain v2 entries.
+const (
+	changedEntry   = '1'
+	renamedEntry   = '2'
+	unmergedEntry  = 'u'
+	untrackedEntry = '?'
+	ignoredEntry   = '!'
+)
+
+// An entry is a porcelain v2 entry, describing the status of a path.
+type entry struct {
+	kind byte
+	xy   string // index and working tree status, for changed, renamed and unmerged entries.
+	sub  string // submodule state, for changed, renamed and unmerged entries.
+	path string
+}
+
+// parsePorcelainV2 parses the output of 'git status --porcelain=v2 -z'.
+// Header lines are ignored.
+func parsePorcelainV2(out string) ([]entry, error) {
+	var entries []entry
+
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		line := fields[i]
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		e := entry{kind: line[0]}
+		var nfields int
+		switch e.kind {
+		case changedEntry:
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			nfields = 9
+		case renamedEntry:
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, followed by <origPath>
+			nfields = 10
+			i++
+		case unmergedEntry:
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			nfields = 11
+		case untrackedEntry, ignoredEntry:
+			// ? <path>
+			nfields = 2
+		default:
+			return nil, fmt.Errorf("unexpected porcelain v2 entry %q", line)
+		}
+
+		parts := strings.SplitN(line, " ", nfields)
+		if len(parts) != nfields {
+			return nil, fmt.Errorf("unexpected porcelain v2 entry %q", line)
+		}
+		if nfields > 2 {
+			e.xy, e.sub = parts[1], parts[2]
+			if len(e.xy) != 2 {
+				return nil, fmt.Errorf("unexpected porcelain v2 entry %q", line)
+			}
+		}
+		e.path = parts[nfields-1]
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Status returns the status of the working tree, like gitstatus.New, except
+// that untracked files are searched according to the untracked mode, which is
+// one of "all", "normal" or "no", as for git status --untracked-files.
+func (r *Repo) Status(untracked string) (*gitstatus.Status, error) {
+	out, err := r.run("status", "--porcelain=v2", "--branch", "-z", "--untracked-files="+untracked)
+	if err != nil {
+		return nil, err
+	}
+
+	var por gitstatus.Porcelain
+	if err := parseBranchHeaders(&por, out); err != nil {
+		return nil, err
+	}
+
+	entries, err := parsePorcelainV2(out)
+	if err != nil {
+		return nil, err
+	}
+	countFiles(&por, entries)
+
+	stats, err := r.Stats(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// All successive commands require at least one commit.
+	if por.IsInitial {
+		return &gitstatus.Status{Porcelain: por}, nil
+	}
+
+	out, err = r.run("stash", "list")
+	if err != nil {
+		return nil, err
+	}
+	nstashed := strings.Count(out, "\n")
+
+	out, err = r.run("rev-parse", "--git-dir", "--short", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected rev-parse output %q", out)
+	}
+
+	return &gitstatus.Status{
+		Porcelain:  por,
+		State:      treeState(lines[0]),
+		HEAD:       lines[1],
+		NumStashed: nstashed,
+		IsClean:    por.NumStaged+por.NumConflicts+por.NumModified+por.NumUntracked == 0,
+		Insertions: stats.Insertions,
+		Deletions:  stats.Deletions,
+	}, nil
+}
+
+// parseBranchHeaders fills por with the branch headers of the output of 'git
+// status --porcelain=v2 --branch -z'.
+func parseBranchHeaders(por *gitstatus.Porcelain, out string) error {
+	for _, line := range strings.Split(out, "\x00") {
+		if !strings.HasPrefix(line, "# ") {
+			// Headers come first.
+			break
+		}
+
+		key, val, _ := strings.Cut(line[2:], " ")
+		switch key {
+		case "branch.oid":
+			por.IsInitial = val == "(initial)"
+		case "branch.head":
+			if val == "(detached)" {
+				por.IsDetached = true
+			} else {
+				por.LocalBranch = val
+			}
+		case "branch.upstream":
+			por.RemoteBranch = val
+		case "branch.ab":
+			if _, err := fmt.Sscanf(val, "+%d -%d", &por.AheadCount, &por.BehindCount); err != nil {
+				return fmt.Errorf("unexpected porcelain v2 header %q: %v", line, err)
+			}
+		}
+	}
+	return nil
+}
+
+// countFiles counts the files in entries the same way gitstatus does.
+func countFiles(por *gitstatus.Porcelain, entries []entry) {
+	for _, e := range entries {
+		if e.kind == ignoredEntry {
+			continue
+		}
+		if e.kind == untrackedEntry {
+			por.NumUntracked++
+			continue
+		}
+
+		// Porcelain v1 uses spaces where porcelain v2 uses dots.
+		x, y := e.xy[0], e.xy[1]
+		if x == '.' {
+			x = ' '
+		}
+		if y == '.' {
+			y = ' '
+		}
+
+		switch {
+		case x == 'U', y == 'U',
+			x == 'A' && y == 'A':
+			por.NumConflicts++
+		case x == 'A' && y == 'M',
+			x == 'M' && y == 'M',
+			x == 'M' && y == 'D',
+			x == 'R' && y == 'M',
+			x == 'R' && y == 'D',
+			x == 'A' && y == 'T':
+			por.NumModified++
+			por.NumStaged++
+		case y == 'M', y == 'D':
+			por.NumModified++
+		default:
+			por.NumStaged++
+		}
+	}
+}
+
+// treeState returns the state of the working tree which Git directory is
+// gitdir, the same way gitstatus does.
+func treeState(gitdir string) gitstatus.TreeState {
+	exists := func(elem ...string) bool {
+		_, err := os.Stat(filepath.Join(append([]string{gitdir}, elem...)...))
+		return !errors.Is(err, fs.ErrNotExist)
+	}
+
+	switch {
+	case exists("rebase-merge"):
+		return gitstatus.Rebasing
+	case exists("rebase-apply"):
+		switch {
+		case exists("rebase-apply", "rebasing"):
+			return gitstatus.Rebasing
+		case exists("rebase-apply", "applying"):
+			return gitstatus.AM
+		}
+		return gitstatus.AMRebase
+	case exists("MERGE_HEAD"):
+		return gitstatus.Merging
+	case exists("CHERRY_PICK_HEAD"):
+		return gitstatus.CherryPicking
+	case exists("REVERT_HEAD"):
+		return gitstatus.Reverting
+	case exists("BISECT_LOG"):
+		return gitstatus.Bisecting
+	}
+	return gitstatus.Default
+}
+
+// TrackedFiles returns the number of files in the index, which is read from
+// the index header, without listing them.
+func (r *Repo) TrackedFiles() (int, error) {
+	out, err := r.run("rev-parse", "--git-path", "index")
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Open(strings.TrimSpace(out))
+	if errors.Is(err, fs.ErrNotExist) {
+		// No index yet.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	// The index starts with a 12-byte header: the "DIRC" signature, the
+	// version number and the number of entries, in network byte order.
+	var hdr [12]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return 0, fmt.Errorf("read index header: %v", err)
+	}
+	if string(hdr[:4]) != "DIRC" {
+		return 0, fmt.Errorf("unexpected index signature %q", hdr[:4])
+	}
+	return int(binary.BigEndian.Uint32(hdr[8:])), nil
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arl/gitstatus"
+)
+
+// porcelainV2 joins lines into a 'git status --porcelain=v2 -z' output.
+func porcelainV2(lines ...string) string {
+	return strings.Join(lines, "\x00") + "\x00"
+}
+
+func Test_parsePorcelainV2(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []entry
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			out:  "",
+		},
+		{
+			name: "headers only",
+			out:  porcelainV2("# branch.oid 3f2a1b0", "# branch.head main"),
+		},
+		{
+			name: "all kinds of entries",
+			out: porcelainV2(
+				"# branch.head main",
+				"1 M. N... 100644 100644 100644 3f2a1b0 3f2a1b0 modified file.txt",
+				"2 R. N... 100644 100644 100644 3f2a1b0 3f2a1b0 R100 new name.txt", "old name.txt",
+				"u UU N... 100644 100644 100644 100644 3f2a1b0 3f2a1b0 3f2a1b0 conflict.txt",
+				"1 .M SC.. 160000 160000 160000 3f2a1b0 3f2a1b0 sub",
+				"? untracked.txt",
+				"! ignored/",
+			),
+			want: []entry{
+				{kind: changedEntry, xy: "M.", sub: "N...", path: "modified file.txt"},
+				{kind: renamedEntry, xy: "R.", sub: "N...", path: "new name.txt"},
+				{kind: unmergedEntry, xy: "UU", sub: "N...", path: "conflict.txt"},
+				{kind: changedEntry, xy: ".M", sub: "SC..", path: "sub"},
+				{kind: untrackedEntry, path: "untracked.txt"},
+				{kind: ignoredEntry, path: "ignored/"},
+			},
+		},
+		{
+			name:    "unexpected entry",
+			out:     porcelainV2("3 M. N..."),
+			wantErr: true,
+		},
+		{
+			name:    "truncated entry",
+			out:     porcelainV2("1 M. N... 100644"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePorcelainV2(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePorcelainV2() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePorcelainV2() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_countChanges(t *testing.T) {
+	entries, err := parsePorcelainV2(porcelainV2(
+		"1 A. N... 000000 100644 100644 0000000 3f2a1b0 added.txt",
+		"1 .A N... 000000 000000 100644 0000000 0000000 intent-to-add.txt",
+		"1 D. N... 100644 000000 000000 3f2a1b0 0000000 deleted.txt",
+		"1 .D N... 100644 100644 000000 3f2a1b0 3f2a1b0 deleted-in-worktree.txt",
+		"1 MD N... 100644 100644 000000 3f2a1b0 3f2a1b0 modified-then-deleted.txt",
+		"2 R. N... 100644 100644 100644 3f2a1b0 3f2a1b0 R100 renamed.txt", "orig.txt",
+		"2 C. N... 100644 100644 100644 3f2a1b0 3f2a1b0 C75 copied.txt", "orig.txt",
+		"1 T. N... 100644 120000 120000 3f2a1b0 3f2a1b0 link",
+		"1 .T N... 100644 100644 120000 3f2a1b0 3f2a1b0 link-in-worktree",
+		"1 M. N... 100644 100644 100644 3f2a1b0 3f2a1b0 modified.txt",
+		"u AA N... 000000 100644 100644 100644 0000000 3f2a1b0 3f2a1b0 both-added.txt",
+		"? untracked.txt",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Changes{Added: 2, Deleted: 3, Renamed: 2, TypeChanged: 2}
+	if got := countChanges(entries); got != want {
+		t.Errorf("countChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(local, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	writeFile("modified.txt", "a\n")
+	writeFile("deleted.txt", "deleted\n")
+	writeFile("renamed.txt", "renamed\n")
+	runGit(t, local, "add", ".")
+	runGit(t, local, "commit", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Unpushed commit")
+	writeFile("modified.txt", "stashed\n")
+	runGit(t, local, "stash")
+
+	writeFile("modified.txt", "a\nb\nc\n")
+	writeFile("staged.txt", "staged\n")
+	runGit(t, local, "add", "staged.txt")
+	runGit(t, local, "mv", "renamed.txt", "new name.txt")
+	runGit(t, local, "rm", "-q", "deleted.txt")
+	writeFile("untracked.txt", "a\n")
+	writeFile("untracked/a.txt", "a\n")
+	writeFile("untracked/b.txt", "a\n")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	// In normal mode, Status must return the same status as gitstatus.
+	want, err := gitstatus.New()
+	if err != nil {
+		t.Fatalf("gitstatus.New() error: %v", err)
+	}
+	got, err := repo.Status("normal")
+	if err != nil {
+		t.Fatalf("Status(normal) error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Status(normal) = %+v, want %+v", got, want)
+	}
+	if got.NumStashed != 1 || got.AheadCount != 1 || got.NumStaged != 3 || got.NumModified != 1 {
+		t.Errorf("Status(normal) = %+v, some counts are missing", got)
+	}
+
+	tests := []struct {
+		mode string
+		want int
+	}{
+		{mode: "all", want: 3},
+		{mode: "normal", want: 2},
+		{mode: "no", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			st, err := repo.Status(tt.mode)
+			if err != nil {
+				t.Fatalf("Status(%s) error: %v", tt.mode, err)
+			}
+			if st.NumUntracked != tt.want {
+				t.Errorf("Status(%s).NumUntracked = %d, want %d", tt.mode, st.NumUntracked, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusInitial(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(dir)
+
+	want, err := gitstatus.New()
+	if err != nil {
+		t.Fatalf("gitstatus.New() error: %v", err)
+	}
+	got, err := New(context.Background()).Status("normal")
+	if err != nil {
+		t.Fatalf("Status(normal) error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Status(normal) = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackedFiles(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+
+	t.Chdir(dir)
+	repo := New(context.Background())
+
+	n, err := repo.TrackedFiles()
+	if err != nil {
+		t.Fatalf("TrackedFiles() error: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("TrackedFiles() = %d, want 0", n)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", ".")
+
+	n, err = repo.TrackedFiles()
+	if err != nil {
+		t.Fatalf("TrackedFiles() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("TrackedFiles() = %d, want 3", n)
+	}
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Submodules holds the number of submodules requiring attention, by kind.
+type Submodules struct {
+	// OutOfSync is the number of submodules which checked out commit is not
+	// the one recorded in the index.
+	OutOfSync int
+
+	// Dirty is the number of submodules with modified content.
+	Dirty int
+
+	// Uninitialized is the number of submodules which are not initialized.
+	Uninitialized int
+}
+
+// Submodules returns the number of out of sync, dirty and uninitialized
+// submodules. Nested submodules are not taken into account.
+func (r *Repo) Submodules() (Submodules, error) {
+	out, err := r.run("submodule", "status")
+	if err != nil {
+		return Submodules{}, err
+	}
+
+	if strings.TrimSpace(out) == "" {
+		// No submodules, no need to look for dirty ones.
+		return Submodules{}, nil
+	}
+	sm := parseSubmoduleStatus(out)
+
+	out, err = r.run("status", "--porcelain=v2", "-z", "--untracked-files=no", "--ignore-submodules=none")
+	if err != nil {
+		return Submodules{}, err
+	}
+
+	entries, err := parsePorcelainV2(out)
+	if err != nil {
+		return Submodules{}, err
+	}
+	for _, e := range entries {
+		// <sub> is S<c><m><u> for submodules, where <m> is M if the
+		// submodule has tracked changes and <u> is U if it has untracked
+		// files.
+		if len(e.sub) == 4 && e.sub[0] == 'S' && (e.sub[2] == 'M' || e.sub[3] == 'U') {
+			sm.Dirty++
+		}
+	}
+	return sm, nil
+}
+
+// parseSubmoduleStatus parses the output of 'git submodule status', where each
+// line starts with '-' if the submodule is not initialized, or '+' if its
+// checked out commit doesn't match the one recorded in the index.
+func parseSubmoduleStatus(out string) Submodules {
+	var sm Submodules
+
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		line := scan.Text()
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			sm.Uninitialized++
+		case '+':
+			sm.OutOfSync++
+		}
+	}
+	return sm
+}
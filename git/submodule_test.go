@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseSubmoduleStatus(t *testing.T) {
+	out := "+245de57178a564ab7e7f88188430664b178c32d0 a (245de57)\n" +
+		" 1b4f9cfed551e2db79d5735e9203a5d98c2e9b48 b (heads/main)\n" +
+		"-1b4f9cfed551e2db79d5735e9203a5d98c2e9b48 c\n" +
+		"-1b4f9cfed551e2db79d5735e9203a5d98c2e9b48 d\n" +
+		"U0000000000000000000000000000000000000000 e\n"
+
+	want := Submodules{OutOfSync: 1, Uninitialized: 2}
+	if got := parseSubmoduleStatus(out); got != want {
+		t.Errorf("parseSubmoduleStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	tmp := t.TempDir()
+	lib := filepath.Join(tmp, "lib")
+	main := filepath.Join(tmp, "main")
+
+	runGit(t, tmp, "init", lib)
+	runGit(t, lib, "commit", "--allow-empty", "-m", "First commit")
+	runGit(t, lib, "commit", "--allow-empty", "-m", "Second commit")
+	runGit(t, tmp, "init", main)
+	runGit(t, main, "commit", "--allow-empty", "-m", "Initial commit")
+
+	t.Chdir(main)
+	repo := New(context.Background())
+
+	sm, err := repo.Submodules()
+	if err != nil {
+		t.Fatalf("Submodules() error: %v", err)
+	}
+	if sm != (Submodules{}) {
+		t.Errorf("Submodules() = %+v, want no submodules", sm)
+	}
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		runGit(t, main, "-c", "protocol.file.allow=always", "submodule", "add", lib, name)
+	}
+	runGit(t, main, "commit", "-m", "Add submodules")
+
+	runGit(t, main, "submodule", "deinit", "c")
+	runGit(t, filepath.Join(main, "a"), "checkout", "HEAD~1")
+	if err := os.WriteFile(filepath.Join(main, "b", "file.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sm, err = repo.Submodules()
+	if err != nil {
+		t.Fatalf("Submodules() error: %v", err)
+	}
+	want := Submodules{OutOfSync: 1, Dirty: 1, Uninitialized: 1}
+	if sm != want {
+		t.Errorf("Submodules() = %+v, want %+v", sm, want)
+	}
+}
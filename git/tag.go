@@ -0,0 +1,70 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Tags describes the tags relative to HEAD.
+type Tags struct {
+	// AtHEAD lists the tags pointing at HEAD.
+	AtHEAD []string
+
+	// Latest is the most recent tag reachable from HEAD.
+	Latest string
+
+	// Distance is the number of commits between Latest and HEAD.
+	Distance int
+}
+
+// Tags returns the tags pointing at HEAD, or if there are none, the latest tag
+// reachable from HEAD. The returned Tags is empty if HEAD can't reach any tag.
+func (r *Repo) Tags() (Tags, error) {
+	out, err := r.run("tag", "--points-at", "HEAD")
+	if err != nil {
+		return Tags{}, err
+	}
+
+	tags := Tags{AtHEAD: strings.Fields(out)}
+	if len(tags.AtHEAD) != 0 {
+		return tags, nil
+	}
+
+	out, err = r.run("describe", "--tags", "--long")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// No reachable tag.
+			return tags, nil
+		}
+		return Tags{}, err
+	}
+
+	tags.Latest, tags.Distance, err = parseDescribe(strings.TrimSpace(out))
+	return tags, err
+}
+
+// parseDescribe parses the output of 'git describe --long', which has the
+// form <tag>-<distance>-g<hash>.
+func parseDescribe(out string) (tag string, distance int, err error) {
+	s := out
+	pos := strings.LastIndexByte(s, '-')
+	if pos == -1 {
+		return "", 0, fmt.Errorf("unexpected describe output %q", out)
+	}
+	s = s[:pos]
+
+	pos = strings.LastIndexByte(s, '-')
+	if pos == -1 {
+		return "", 0, fmt.Errorf("unexpected describe output %q", out)
+	}
+
+	distance, err = strconv.Atoi(s[pos+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("unexpected describe output %q: %v", out, err)
+	}
+	return s[:pos], distance, nil
+}
@@ -0,0 +1,47 @@
+package git
+
+import "testing"
+
+func Test_parseDescribe(t *testing.T) {
+	tests := []struct {
+		out      string
+		tag      string
+		distance int
+		wantErr  bool
+	}{
+		{
+			out:      "v1.4.0-7-g3f2a1b0",
+			tag:      "v1.4.0",
+			distance: 7,
+		},
+		{
+			out:      "v1.4.0-0-g3f2a1b0",
+			tag:      "v1.4.0",
+			distance: 0,
+		},
+		{
+			out:      "release-2024-01-12-g3f2a1b0",
+			tag:      "release-2024-01",
+			distance: 12,
+		},
+		{
+			out:     "v1.4.0",
+			wantErr: true,
+		},
+		{
+			out:     "v1.4.0-g3f2a1b0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			tag, distance, err := parseDescribe(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDescribe(%q) error = %v, wantErr %v", tt.out, err, tt.wantErr)
+			}
+			if tag != tt.tag || distance != tt.distance {
+				t.Errorf("parseDescribe(%q) = %q, %d, want %q, %d", tt.out, tag, distance, tt.tag, tt.distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UpstreamGone reports whether the upstream branch of the given local branch
+// is configured but doesn't exist anymore, for example because it has been
+// deleted on the remote after a pull request has been merged.
+func (r *Repo) UpstreamGone(branch string) (bool, error) {
+	out, err := r.run("for-each-ref", "--format=%(upstream:track,nobracket)", "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "gone", nil
+}
+
+// UpstreamRemote returns the name of the remote of the upstream branch of the
+// given local branch, or an empty string if it has no upstream branch or if it
+// tracks a local branch.
+func (r *Repo) UpstreamRemote(branch string) (string, error) {
+	out, err := r.run("for-each-ref", "--format=%(upstream:remotename)", "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	remote := strings.TrimSpace(out)
+	if remote == "." {
+		// The upstream branch is a local branch.
+		return "", nil
+	}
+	return remote, nil
+}
+
+// UnpublishedCommits returns the number of commits reachable from HEAD that are
+// not on any remote-tracking branch.
+func (r *Repo) UnpublishedCommits() (int, error) {
+	out, err := r.run("rev-list", "--count", "HEAD", "--not", "--remotes")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
+}
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpstreamGone(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "checkout", "-b", "feat")
+	runGit(t, local, "push", "-u", "origin", "feat")
+	runGit(t, local, "checkout", "-b", "local-only")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{branch: "main", want: false},
+		{branch: "feat", want: false},
+		{branch: "local-only", want: false},
+	}
+	for _, tt := range tests {
+		gone, err := repo.UpstreamGone(tt.branch)
+		if err != nil {
+			t.Fatalf("UpstreamGone(%q) error: %v", tt.branch, err)
+		}
+		if gone != tt.want {
+			t.Errorf("UpstreamGone(%q) = %t, want %t", tt.branch, gone, tt.want)
+		}
+	}
+
+	// Deleting the remote branch also deletes the remote-tracking branch.
+	runGit(t, local, "push", "origin", "--delete", "feat")
+
+	gone, err := repo.UpstreamGone("feat")
+	if err != nil {
+		t.Fatalf("UpstreamGone(feat) error: %v", err)
+	}
+	if !gone {
+		t.Errorf("UpstreamGone(feat) = false, want true")
+	}
+}
+
+func TestUnpublishedCommits(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "checkout", "-b", "feat")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	for want := range 3 {
+		n, err := repo.UnpublishedCommits()
+		if err != nil {
+			t.Fatalf("UnpublishedCommits error: %v", err)
+		}
+		if n != want {
+			t.Errorf("UnpublishedCommits() = %d, want %d", n, want)
+		}
+		runGit(t, local, "commit", "--allow-empty", "-m", "Feature commit")
+	}
+}
+
+func TestUpstreamRemote(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", remote)
+	runGit(t, tmp, "clone", remote, local)
+	runGit(t, local, "remote", "add", "team/origin", remote)
+	runGit(t, local, "checkout", "-b", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, local, "push", "-u", "origin", "main")
+	runGit(t, local, "checkout", "-b", "x/main")
+	runGit(t, local, "push", "-u", "team/origin", "x/main:feature/x/main")
+	runGit(t, local, "checkout", "-b", "local-only")
+	runGit(t, local, "checkout", "-b", "tracks-local", "--track", "main")
+
+	t.Chdir(local)
+	repo := New(context.Background())
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{branch: "main", want: "origin"},
+		{branch: "x/main", want: "team/origin"},
+		{branch: "local-only", want: ""},
+		{branch: "tracks-local", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := repo.UpstreamRemote(tt.branch)
+		if err != nil {
+			t.Fatalf("UpstreamRemote(%q) error: %v", tt.branch, err)
+		}
+		if got != tt.want {
+			t.Errorf("UpstreamRemote(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree describes the worktree of the current directory.
+type Worktree struct {
+	// Name is the name of the worktree, which is the base name of its
+	// directory, or empty for the main worktree.
+	Name string
+
+	// Count is the total number of worktrees of the repository, including
+	// the main one.
+	Count int
+
+	// Locked reports whether the worktree is locked.
+	Locked bool
+
+	// Prunable reports whether any worktree of the repository can be pruned,
+	// for example because its directory has been deleted.
+	Prunable bool
+}
+
+// Worktree returns the worktree of the current directory.
+func (r *Repo) Worktree() (Worktree, error) {
+	out, err := r.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return Worktree{}, err
+	}
+	top := strings.TrimSpace(out)
+
+	out, err = r.run("worktree", "list", "--porcelain")
+	if err != nil {
+		return Worktree{}, err
+	}
+	return parseWorktreeList(out, top), nil
+}
+
+// parseWorktreeList parses the output of 'git worktree list --porcelain' and
+// returns the worktree which path is top. The main worktree is always listed
+// first. The current worktree can't be prunable, so Prunable is set if any
+// listed worktree is.
+func parseWorktreeList(out, top string) Worktree {
+	var (
+		wt      Worktree
+		current bool
+	)
+
+	scan := bufio.NewScanner(strings.NewReader(out))
+	for scan.Scan() {
+		key, val, _ := strings.Cut(scan.Text(), " ")
+		switch key {
+		case "worktree":
+			current = filepath.Clean(val) == filepath.Clean(top)
+			if current && wt.Count != 0 {
+				wt.Name = filepath.Base(val)
+			}
+			wt.Count++
+		case "locked":
+			wt.Locked = wt.Locked || current
+		case "prunable":
+			wt.Prunable = true
+		}
+	}
+	return wt
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseWorktreeList(t *testing.T) {
+	const out = `worktree /src/gitmux
+HEAD efcf36877534ad4de040ceab690778db1959480a
+branch refs/heads/main
+
+worktree /src/feat
+HEAD efcf36877534ad4de040ceab690778db1959480a
+branch refs/heads/feat
+locked usb disk
+
+worktree /src/old
+HEAD efcf36877534ad4de040ceab690778db1959480a
+detached
+prunable gitdir file points to non-existent location
+
+`
+	tests := []struct {
+		top  string
+		want Worktree
+	}{
+		{top: "/src/gitmux", want: Worktree{Count: 3, Prunable: true}},
+		{top: "/src/gitmux/", want: Worktree{Count: 3, Prunable: true}},
+		{top: "/src/feat", want: Worktree{Name: "feat", Count: 3, Locked: true, Prunable: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.top, func(t *testing.T) {
+			if got := parseWorktreeList(out, tt.top); got != tt.want {
+				t.Errorf("parseWorktreeList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	tmp := t.TempDir()
+	main := filepath.Join(tmp, "main")
+	feat := filepath.Join(tmp, "feat")
+
+	runGit(t, tmp, "init", main)
+	runGit(t, main, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, main, "worktree", "add", feat)
+	runGit(t, main, "worktree", "lock", feat)
+
+	// Worktree must be found from a subdirectory too.
+	sub := filepath.Join(feat, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	wt, err := New(context.Background()).Worktree()
+	if err != nil {
+		t.Fatalf("Worktree() error: %v", err)
+	}
+	want := Worktree{Name: "feat", Count: 2, Locked: true}
+	if wt != want {
+		t.Errorf("Worktree() = %+v, want %+v", wt, want)
+	}
+}
+
+func TestWorktreePrunable(t *testing.T) {
+	tmp := t.TempDir()
+	main := filepath.Join(tmp, "main")
+	old := filepath.Join(tmp, "old")
+
+	runGit(t, tmp, "init", main)
+	runGit(t, main, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, main, "worktree", "add", old)
+	if err := os.RemoveAll(old); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(main)
+	repo := New(context.Background())
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree() error: %v", err)
+	}
+	want := Worktree{Count: 2, Prunable: true}
+	if wt != want {
+		t.Errorf("Worktree() = %+v, want %+v", wt, want)
+	}
+
+	runGit(t, main, "worktree", "prune")
+
+	wt, err = repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree() error: %v", err)
+	}
+	want = Worktree{Count: 1}
+	if wt != want {
+		t.Errorf("Worktree() after prune = %+v, want %+v", wt, want)
+	}
+}
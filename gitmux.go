@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/arl/gitstatus"
 	"gopkg.in/yaml.v3"
 
+	"github.com/arl/gitmux/git"
 	"github.com/arl/gitmux/json"
 	"github.com/arl/gitmux/tmux"
 )
@@ -103,6 +105,67 @@ func check(err error, dbg bool) {
 	os.Exit(1)
 }
 
+// gitStatus returns the status of the working tree, searching untracked files
+// according to mode (all, normal or no). Untracked files are not searched at
+// all if the repository has more than threshold files, in which case
+// untrackedSkipped is true.
+func gitStatus(ctx context.Context, repo *git.Repo, mode string, threshold int) (st *gitstatus.Status, untrackedSkipped bool, err error) {
+	if threshold > 0 && mode != "no" {
+		n, err := repo.TrackedFiles()
+		if err != nil {
+			return nil, false, err
+		}
+		if n > threshold {
+			mode, untrackedSkipped = "no", true
+		}
+	}
+
+	if mode == "" {
+		// Same as git status default, which follows status.showUntrackedFiles.
+		st, err = gitstatus.NewWithContext(ctx)
+	} else {
+		st, err = repo.Status(mode)
+	}
+	return st, untrackedSkipped, err
+}
+
+// noWorkTree handles the cases where the status can't be retrieved since there's
+// no working tree, as opposed to git failures: outside of a repository, inside
+// the .git directory or in a bare repository. It reports whether the current
+// directory is in one of these cases, after having printed its status, or in
+// debug mode, the reason why there's no working tree.
+func noWorkTree(repo *git.Repo, cfg tmux.Config, dbg bool) bool {
+	fmter := &tmux.Formater{Config: cfg, Repo: repo}
+
+	ok, err := repo.IsRepo()
+	switch {
+	case err != nil:
+		return false
+	case !ok:
+		if dbg {
+			fmt.Fprintln(os.Stderr, "not a git repository")
+			return true
+		}
+		check(fmter.FormatNotARepo(os.Stdout), dbg)
+		return true
+	}
+
+	inside, bare, err := repo.InsideGitDir()
+	if err != nil || !inside {
+		return false
+	}
+	if dbg {
+		if bare {
+			fmt.Fprintln(os.Stderr, "bare repository")
+		} else {
+			fmt.Fprintln(os.Stderr, "inside git directory")
+		}
+		return true
+	}
+	check(fmter.FormatGitDir(os.Stdout, bare), dbg)
+	return true
+}
+
 func main() {
 	ctx, cancel, dir, dbg, cfg := parseOptions()
 	defer cancel()
@@ -117,8 +180,14 @@ func main() {
 		}()
 	}
 
+	repo := git.New(ctx)
+
 	// Retrieve git status.
-	st, err := gitstatus.NewWithContext(ctx)
+	opts := cfg.Tmux.Options
+	st, untrackedSkipped, err := gitStatus(ctx, repo, string(opts.UntrackedMode), opts.UntrackedFilesThreshold)
+	if err != nil && noWorkTree(repo, cfg.Tmux, dbg) {
+		return
+	}
 	check(err, dbg)
 
 	// Interface that writes a particular representation of a gitstatus.Status
@@ -127,10 +196,19 @@ func main() {
 	}
 
 	// Set defauit formater.
-	var fmter formater = &tmux.Formater{Config: cfg.Tmux}
+	var fmter formater = &tmux.Formater{Config: cfg.Tmux, Repo: repo, UntrackedSkipped: untrackedSkipped}
 	if dbg {
 		fmter = &json.Formater{}
 	}
 
 	check(fmter.Format(os.Stdout, st), dbg)
+
+	// Fetch in the background, once the status has been printed. Since the
+	// status is already out, failing to fetch doesn't make gitmux fail.
+	if cfg.Tmux.Options.AutoFetch {
+		err := repo.AutoFetch(time.Duration(cfg.Tmux.Options.AutoFetchInterval))
+		if err != nil && dbg {
+			fmt.Fprintln(os.Stderr, "auto fetch error:", err)
+		}
+	}
 }
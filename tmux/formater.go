@@ -3,11 +3,18 @@ package tmux
 import (
 	"fmt"
 	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/arl/gitstatus"
 	"gopkg.in/yaml.v3"
+
+	"github.com/arl/gitmux/git"
 )
 
 // Config is the configuration of the Git status tmux formatter.
@@ -37,8 +44,57 @@ type symbols struct {
 	Stashed   string // Stashed is the string shown before the count of stash entries.
 	Clean     string // Clean is the string shown when the working tree is clean.
 
+	Added       string // Added is the string shown before the count of added files.
+	Deleted     string // Deleted is the string shown before the count of deleted files.
+	Renamed     string // Renamed is the string shown before the count of renamed or copied files.
+	TypeChanged string `yaml:"type_changed"` // TypeChanged is the string shown before the count of files which type changed.
+
 	Insertions string // Insertions is the string shown before the count of inserted lines.
 	Deletions  string // Deletions is the string shown before the count of deleted lines.
+
+	StagedInsertions   string `yaml:"staged_insertions"`   // StagedInsertions is the string shown before the count of inserted lines in the staging area.
+	StagedDeletions    string `yaml:"staged_deletions"`    // StagedDeletions is the string shown before the count of deleted lines in the staging area.
+	UnstagedInsertions string `yaml:"unstaged_insertions"` // UnstagedInsertions is the string shown before the count of inserted lines in the working tree.
+	UnstagedDeletions  string `yaml:"unstaged_deletions"`  // UnstagedDeletions is the string shown before the count of deleted lines in the working tree.
+
+	Tag string // Tag is the string shown before the tags at HEAD or the latest reachable tag.
+
+	BaseAhead  string `yaml:"base_ahead"`  // BaseAhead is the string shown before the ahead count for the HEAD/base branch divergence.
+	BaseBehind string `yaml:"base_behind"` // BaseBehind is the string shown before the behind count for the HEAD/base branch divergence.
+
+	PushAhead  string `yaml:"push_ahead"`  // PushAhead is the string shown before the ahead count for the local/push branch divergence.
+	PushBehind string `yaml:"push_behind"` // PushBehind is the string shown before the behind count for the local/push branch divergence.
+
+	Gone       string // Gone is the string shown after the upstream branch when it doesn't exist anymore.
+	NoUpstream string `yaml:"no_upstream"` // NoUpstream is the string shown instead of the upstream branch when the local branch has none.
+	RemoteSame string `yaml:"remote_same"` // RemoteSame is the string shown instead of the upstream branch when it has the same name as the local branch, with remote_collapse set to symbol.
+
+	Ignored string // Ignored is the string shown before the count of ignored paths.
+
+	SubmoduleOutOfSync     string `yaml:"submodule_out_of_sync"`   // SubmoduleOutOfSync is the string shown before the count of submodules not at their recorded commit.
+	SubmoduleDirty         string `yaml:"submodule_dirty"`         // SubmoduleDirty is the string shown before the count of submodules with modified content.
+	SubmoduleUninitialized string `yaml:"submodule_uninitialized"` // SubmoduleUninitialized is the string shown before the count of uninitialized submodules.
+
+	Worktree         string // Worktree is the string shown before the worktree name.
+	WorktreeLocked   string `yaml:"worktree_locked"`   // WorktreeLocked is the string shown after the worktree name when it's locked.
+	WorktreePrunable string `yaml:"worktree_prunable"` // WorktreePrunable is the string shown after the worktree name when any worktree can be pruned.
+
+	NotARepo string `yaml:"not_a_repo"` // NotARepo is the string shown instead of the Git status outside of a repository.
+	Bare     string // Bare is the string shown before the branch in a bare repository.
+	GitDir   string `yaml:"git_dir"` // GitDir is the string shown instead of the Git status inside the .git directory.
+
+	Shallow string // Shallow is the string shown when the repository is a shallow clone.
+	Partial string // Partial is the string shown when the repository is a partial clone.
+	Sparse  string // Sparse is the string shown when sparse checkout is enabled.
+
+	LFSPointers string `yaml:"lfs_pointers"` // LFSPointers is the string shown before the count of LFS files not downloaded.
+	LFSUnpushed string `yaml:"lfs_unpushed"` // LFSUnpushed is the string shown before the count of LFS objects to push.
+	LFSLocks    string `yaml:"lfs_locks"`    // LFSLocks is the string shown before the count of LFS files locked by the current user.
+
+	Identity     string // Identity is the string shown before the user email.
+	NoSigningKey string `yaml:"no_signing_key"` // NoSigningKey is the string shown after the user email when commits are signed without a signing key.
+
+	BranchPolicy string `yaml:"branch_policy"` // BranchPolicy is the string shown after the local branch name when it violates the branch_policy option.
 }
 
 type styles struct {
@@ -57,8 +113,69 @@ type styles struct {
 	Stashed   string // Stashed is the style string printed before the stash entries count.
 	Clean     string // Clean is the style string printed before the clean symbols.
 
+	StashedOld string `yaml:"stashed_old"` // StashedOld replaces Stashed when the oldest stash entry is older than the stash_age_threshold option.
+
+	Added       string // Added is the style string printed before the added files count.
+	Deleted     string // Deleted is the style string printed before the deleted files count.
+	Renamed     string // Renamed is the style string printed before the renamed or copied files count.
+	TypeChanged string `yaml:"type_changed"` // TypeChanged is the style string printed before the type changed files count.
+
 	Insertions string // Insertions is the style string printed before the count of inserted lines.
 	Deletions  string // Deletions is the style string printed before the count of deleted lines.
+
+	StagedInsertions   string `yaml:"staged_insertions"`   // StagedInsertions is the style string printed before the count of inserted lines in the staging area.
+	StagedDeletions    string `yaml:"staged_deletions"`    // StagedDeletions is the style string printed before the count of deleted lines in the staging area.
+	UnstagedInsertions string `yaml:"unstaged_insertions"` // UnstagedInsertions is the style string printed before the count of inserted lines in the working tree.
+	UnstagedDeletions  string `yaml:"unstaged_deletions"`  // UnstagedDeletions is the style string printed before the count of deleted lines in the working tree.
+
+	Tag string // Tag is the style string printed before the tags.
+
+	CommitAge     string `yaml:"commit_age"`     // CommitAge is the style string printed before the age of the last commit.
+	CommitAgeOld  string `yaml:"commit_age_old"` // CommitAgeOld replaces CommitAge when the last commit is older than the commit_age_threshold option.
+	CommitSubject string `yaml:"commit_subject"` // CommitSubject is the style string printed before the subject of the last commit.
+
+	FetchAge    string `yaml:"fetch_age"`     // FetchAge is the style string printed before the time since the last fetch.
+	FetchAgeOld string `yaml:"fetch_age_old"` // FetchAgeOld replaces FetchAge when the last fetch is older than the fetch_age_threshold option.
+
+	BaseDivergence string `yaml:"base_divergence"` // BaseDivergence is the style string printed before the base branch divergence counts/symbols.
+
+	Push           string // Push is the style string printed before the push branch.
+	PushDivergence string `yaml:"push_divergence"` // PushDivergence is the style string printed before the push branch divergence counts/symbols.
+
+	Gone       string // Gone replaces Remote when the upstream branch doesn't exist anymore.
+	NoUpstream string `yaml:"no_upstream"` // NoUpstream is the style string printed before the no_upstream symbol.
+
+	Ignored      string // Ignored is the style string printed before the count of ignored paths.
+	IgnoredAlert string `yaml:"ignored_alert"` // IgnoredAlert replaces Ignored when the ignored_threshold or ignored_size_threshold option is exceeded.
+
+	SubmoduleOutOfSync     string `yaml:"submodule_out_of_sync"`   // SubmoduleOutOfSync is the style string printed before the count of submodules not at their recorded commit.
+	SubmoduleDirty         string `yaml:"submodule_dirty"`         // SubmoduleDirty is the style string printed before the count of submodules with modified content.
+	SubmoduleUninitialized string `yaml:"submodule_uninitialized"` // SubmoduleUninitialized is the style string printed before the count of uninitialized submodules.
+
+	Worktree         string // Worktree is the style string printed before the worktree name.
+	WorktreeLocked   string `yaml:"worktree_locked"`   // WorktreeLocked is the style string printed before the worktree_locked symbol.
+	WorktreePrunable string `yaml:"worktree_prunable"` // WorktreePrunable is the style string printed before the worktree_prunable symbol.
+
+	Repo string // Repo is the style string printed before the repository name.
+	Path string // Path is the style string printed before the path relative to the repository top-level directory.
+
+	NotARepo string `yaml:"not_a_repo"` // NotARepo is the style string printed before the not_a_repo symbol.
+
+	Shallow string // Shallow is the style string printed before the shallow symbol.
+	Partial string // Partial is the style string printed before the partial symbol.
+	Sparse  string // Sparse is the style string printed before the sparse symbol.
+
+	LFSPointers string `yaml:"lfs_pointers"` // LFSPointers is the style string printed before the count of LFS files not downloaded.
+	LFSUnpushed string `yaml:"lfs_unpushed"` // LFSUnpushed is the style string printed before the count of LFS objects to push.
+	LFSLocks    string `yaml:"lfs_locks"`    // LFSLocks is the style string printed before the count of LFS files locked by the current user.
+
+	Identity     string // Identity is the style string printed before the user email.
+	NoSigningKey string `yaml:"no_signing_key"` // NoSigningKey is the style string printed before the no_signing_key symbol.
+
+	BranchPolicy    string `yaml:"branch_policy"`    // BranchPolicy replaces Branch when the local branch violates the branch_policy option.
+	BranchProtected string `yaml:"branch_protected"` // BranchProtected replaces Branch when the local branch is one of the protected_branches.
+
+	BranchProtectedAlert string `yaml:"branch_protected_alert"` // BranchProtectedAlert replaces BranchProtected when the working tree is dirty or ahead of the upstream branch.
 }
 
 const (
@@ -87,6 +204,179 @@ func (d *direction) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+const (
+	collapseNone   collapse = "none"
+	collapseRemote collapse = "remote"
+	collapseSymbol collapse = "symbol"
+	collapseHide   collapse = "hide"
+)
+
+// collapse defines how the upstream branch is shown when it has the same name
+// as the local branch.
+type collapse string
+
+func (c *collapse) UnmarshalYAML(value *yaml.Node) error {
+	s := ""
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("error decoding 'collapse': %v", s)
+	}
+	switch collapse(s) {
+	case collapseNone:
+		*c = collapseNone
+	case collapseRemote:
+		*c = collapseRemote
+	case collapseSymbol:
+		*c = collapseSymbol
+	case collapseHide:
+		*c = collapseHide
+	default:
+		return fmt.Errorf("'collapse': unexpected value %v", s)
+	}
+	return nil
+}
+
+const (
+	untrackedAll    untrackedMode = "all"
+	untrackedNormal untrackedMode = "normal"
+	untrackedNo     untrackedMode = "no"
+)
+
+// untrackedMode defines how untracked files are searched, like the
+// --untracked-files option of git status.
+type untrackedMode string
+
+func (m *untrackedMode) UnmarshalYAML(value *yaml.Node) error {
+	s := ""
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("error decoding 'untracked_mode': %v", s)
+	}
+	switch untrackedMode(s) {
+	case untrackedAll:
+		*m = untrackedAll
+	case untrackedNormal:
+		*m = untrackedNormal
+	case untrackedNo:
+		*m = untrackedNo
+	default:
+		return fmt.Errorf("'untracked_mode': unexpected value %v", s)
+	}
+	return nil
+}
+
+const (
+	stashAgeNone   stashAge = "none"
+	stashAgeNewest stashAge = "newest"
+	stashAgeOldest stashAge = "oldest"
+)
+
+// stashAge defines which stash entry age is shown after the stash entries
+// count.
+type stashAge string
+
+func (a *stashAge) UnmarshalYAML(value *yaml.Node) error {
+	s := ""
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("error decoding 'stash_age': %v", s)
+	}
+	switch stashAge(s) {
+	case stashAgeNone:
+		*a = stashAgeNone
+	case stashAgeNewest:
+		*a = stashAgeNewest
+	case stashAgeOldest:
+		*a = stashAgeOldest
+	default:
+		return fmt.Errorf("'stash_age': unexpected value %v", s)
+	}
+	return nil
+}
+
+// fetchInterval is the minimum duration between two automatic fetches. It must
+// be positive, otherwise every gitmux invocation would start a fetch.
+type fetchInterval time.Duration
+
+func (i *fetchInterval) UnmarshalYAML(value *yaml.Node) error {
+	var d time.Duration
+	if err := value.Decode(&d); err != nil {
+		return fmt.Errorf("error decoding 'auto_fetch_interval': %v", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("'auto_fetch_interval': must be positive, got %v", d)
+	}
+	*i = fetchInterval(d)
+	return nil
+}
+
+// branchPolicy is a list of regular expressions branch names must match.
+type branchPolicy []*regexp.Regexp
+
+func (p *branchPolicy) UnmarshalYAML(value *yaml.Node) error {
+	var exprs []string
+	if err := value.Decode(&exprs); err != nil {
+		return fmt.Errorf("error decoding 'branch_policy': %v", err)
+	}
+
+	policy := make(branchPolicy, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("'branch_policy': invalid regular expression %q: %v", expr, err)
+		}
+		policy = append(policy, re)
+	}
+	*p = policy
+	return nil
+}
+
+// allows reports whether branch matches any of the policy regular
+// expressions. An empty policy allows any branch.
+func (p branchPolicy) allows(branch string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, re := range p {
+		if re.MatchString(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// byteSize is a size in bytes, which can be written with a K, M, G or T
+// suffix, for example 500M or 2G. Units are powers of 1024.
+type byteSize int64
+
+func (b *byteSize) UnmarshalYAML(value *yaml.Node) error {
+	s := ""
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("error decoding 'size': %v", s)
+	}
+
+	num, mult := s, int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K', 'k':
+			mult = 1 << 10
+		case 'M', 'm':
+			mult = 1 << 20
+		case 'G', 'g':
+			mult = 1 << 30
+		case 'T', 't':
+			mult = 1 << 40
+		}
+		if mult != 1 {
+			num = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("'size': unexpected value %v", s)
+	}
+	*b = byteSize(n * mult)
+	return nil
+}
+
 type options struct {
 	BranchMaxLen      int       `yaml:"branch_max_len"`
 	BranchTrim        direction `yaml:"branch_trim"`
@@ -95,12 +385,110 @@ type options struct {
 	DivergenceSpace   bool      `yaml:"divergence_space"`
 	SwapDivergence    bool      `yaml:"swap_divergence"`
 	FlagsWithoutCount bool      `yaml:"flags_without_count"`
+
+	CommitAgeThreshold  time.Duration `yaml:"commit_age_threshold"`
+	CommitSubjectMaxLen int           `yaml:"commit_subject_max_len"`
+	FetchAgeThreshold   time.Duration `yaml:"fetch_age_threshold"`
+	AutoFetch           bool          `yaml:"auto_fetch"`
+	AutoFetchInterval   fetchInterval `yaml:"auto_fetch_interval"`
+	BaseBranch          string        `yaml:"base_branch"`
+	NoUpstreamCount     bool          `yaml:"no_upstream_count"`
+
+	RemoteCollapse collapse          `yaml:"remote_collapse"`
+	RemoteAliases  map[string]string `yaml:"remote_aliases"`
+
+	IgnoredSize          bool     `yaml:"ignored_size"`
+	IgnoredThreshold     int      `yaml:"ignored_threshold"`
+	IgnoredSizeThreshold byteSize `yaml:"ignored_size_threshold"`
+
+	UntrackedMode           untrackedMode `yaml:"untracked_mode"`
+	UntrackedFilesThreshold int           `yaml:"untracked_files_threshold"`
+
+	RepoFromRemote bool `yaml:"repo_from_remote"`
+
+	StashAge          stashAge      `yaml:"stash_age"`
+	StashAgeThreshold time.Duration `yaml:"stash_age_threshold"`
+	StashBranchCount  bool          `yaml:"stash_branch_count"`
+
+	IdentityAliases map[string]string `yaml:"identity_aliases"`
+
+	BranchPolicy      branchPolicy `yaml:"branch_policy"`
+	ProtectedBranches []string     `yaml:"protected_branches"`
+
+	// Truncate overrides truncation options for specific layout components.
+	Truncate map[string]truncateOptions `yaml:"truncate"`
+}
+
+// truncateOptions defines how a layout component is truncated. Unset fields
+// default to the global options.
+type truncateOptions struct {
+	Max      *int      `yaml:"max"`
+	Dir      direction `yaml:"dir"`
+	Ellipsis *string   `yaml:"ellipsis"`
+}
+
+// A Repo provides the Git repository information that is not part of
+// gitstatus.Status. Repo methods are only called when the layout contains a
+// component requiring them.
+type Repo interface {
+	Tags() (git.Tags, error)
+	LastCommit() (git.Commit, error)
+	LastFetch() (time.Time, error)
+	BaseDivergence(base string) (git.Divergence, error)
+	PushDivergence() (git.Divergence, error)
+	UpstreamGone(branch string) (bool, error)
+	UpstreamRemote(branch string) (string, error)
+	UnpublishedCommits() (int, error)
+	Changes() (git.Changes, error)
+	Stats(staged bool) (git.Stats, error)
+	Ignored(size bool) (git.Ignored, error)
+	Submodules() (git.Submodules, error)
+	Worktree() (git.Worktree, error)
+	Location() (git.Location, error)
+	Head() (git.Head, error)
+	RepoFlags() (git.RepoFlags, error)
+	LFS() (git.LFS, error)
+	Stashes() ([]git.Stash, error)
+	Identity() (git.Identity, error)
+	RemoteURL() (string, error)
 }
 
 // A Formater formats git status to a tmux style string.
 type Formater struct {
 	Config
-	st *gitstatus.Status
+	Repo Repo
+
+	// UntrackedSkipped reports whether untracked files were not searched
+	// because the repository has more than untracked_files_threshold files,
+	// in which case their count is unknown.
+	UntrackedSkipped bool
+
+	st    *gitstatus.Status
+	cache repoCache
+}
+
+// repoCache holds the results of the Repo calls shared by several components,
+// so that they run git once per Format call.
+type repoCache struct {
+	push       cached[git.Divergence]
+	lastCommit cached[git.Commit]
+	location   cached[git.Location]
+}
+
+// cached memoizes the result of a Repo call.
+type cached[T any] struct {
+	done bool
+	val  T
+	err  error
+}
+
+// get returns the result of call, which is only called the first time.
+func (c *cached[T]) get(call func() (T, error)) (T, error) {
+	if !c.done {
+		c.val, c.err = call()
+		c.done = true
+	}
+	return c.val, c.err
 }
 
 // truncate returns s, truncated so that it is no more than max runes long.
@@ -149,16 +537,71 @@ func truncate(s, ellipsis string, max int, dir direction) string {
 	return string(runes)
 }
 
+// timeNow returns the current time, it is replaced in tests.
+var timeNow = time.Now
+
+// formatAge formats d, rounded down to its largest unit, like 45s, 3h or 2d.
+func formatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < day:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d < 365*day:
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return fmt.Sprintf("%dy", d/(365*day))
+}
+
+// formatSize formats n bytes, rounded down to its largest unit, like 512B, 3K
+// or 2G.
+func formatSize(n int64) string {
+	const units = "KMGT"
+
+	if n < 1<<10 {
+		return fmt.Sprintf("%dB", n)
+	}
+	i := 0
+	for n >= 1<<20 && i < len(units)-1 {
+		n >>= 10
+		i++
+	}
+	return fmt.Sprintf("%d%c", n>>10, units[i])
+}
+
+// truncateComp is like truncate, but uses the truncate options of the given
+// layout component, if any, instead of max, dir and the ellipsis option.
+func (f *Formater) truncateComp(comp, s string, max int, dir direction) string {
+	ellipsis := f.Options.Ellipsis
+	if t, ok := f.Options.Truncate[comp]; ok {
+		if t.Max != nil {
+			max = *t.Max
+		}
+		if t.Dir != "" {
+			dir = t.Dir
+		}
+		if t.Ellipsis != nil {
+			ellipsis = *t.Ellipsis
+		}
+	}
+
+	return truncate(s, ellipsis, max, dir)
+}
+
 // Format writes st as json into w.
 func (f *Formater) Format(w io.Writer, st *gitstatus.Status) error {
 	defer fmt.Fprintf(w, "%s", f.Styles.Clear)
 
 	f.st = st
+	f.cache = repoCache{}
 
 	// Overall working tree state
 	if f.st.IsInitial {
-		branch := truncate(f.st.LocalBranch, f.Options.Ellipsis, f.Options.BranchMaxLen, f.Options.BranchTrim)
-		s := fmt.Sprintf("%s%s%s [no commits yet] %s", f.Styles.Clear, f.Styles.Branch, branch, f.flags())
+		s := fmt.Sprintf("%s [no commits yet] %s", f.currentRef(), f.flags())
 		_, err := io.WriteString(w, s)
 		return err
 	}
@@ -167,6 +610,44 @@ func (f *Formater) Format(w io.Writer, st *gitstatus.Status) error {
 	return err
 }
 
+// FormatNotARepo writes the not_a_repo symbol into w, if any. It's used
+// instead of Format when the current directory is not in a repository.
+func (f *Formater) FormatNotARepo(w io.Writer) error {
+	if f.Symbols.NotARepo == "" {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(w, "%s%s%s%s", f.Styles.Clear, f.Styles.NotARepo, f.Symbols.NotARepo, resetStyles)
+	return err
+}
+
+// FormatGitDir writes the status of a repository without working tree into
+// w. It's used instead of Format when the current directory is inside the
+// .git directory, or in a bare repository, in which case only the current
+// branch is shown.
+func (f *Formater) FormatGitDir(w io.Writer, bare bool) error {
+	if !bare {
+		_, err := fmt.Fprintf(w, "%s%s%s%s", f.Styles.Clear, f.Styles.State, f.Symbols.GitDir, resetStyles)
+		return err
+	}
+
+	head, err := f.Repo.Head()
+	if err != nil {
+		return err
+	}
+
+	f.st = &gitstatus.Status{
+		Porcelain: gitstatus.Porcelain{
+			LocalBranch: head.Branch,
+			IsDetached:  head.Branch == "",
+		},
+		HEAD:    head.Hash,
+		IsClean: true, // there's no working tree
+	}
+	_, err = fmt.Fprintf(w, "%s%s%s%s%s", f.Styles.Clear, f.Styles.State, f.Symbols.Bare, f.specialState(), resetStyles)
+	return err
+}
+
 const resetStyles = "#[fg=default,bg=default]"
 
 func (f *Formater) format() string {
@@ -200,6 +681,40 @@ func (f *Formater) format() string {
 			comps = append(comps, f.flags())
 		case "stats":
 			comps = append(comps, f.stats())
+		case "stats-staged":
+			comps = append(comps, f.stagedStats())
+		case "stats-unstaged":
+			comps = append(comps, f.unstagedStats())
+		case "tag":
+			comps = append(comps, f.tag())
+		case "commit-age":
+			comps = append(comps, f.commitAge())
+		case "commit-subject":
+			comps = append(comps, f.commitSubject())
+		case "fetch-age":
+			comps = append(comps, f.fetchAge())
+		case "base-divergence":
+			comps = append(comps, f.baseDivergence())
+		case "push-branch":
+			comps = append(comps, f.pushBranch())
+		case "push-divergence":
+			comps = append(comps, f.pushDivergence())
+		case "ignored":
+			comps = append(comps, f.ignored())
+		case "submodules":
+			comps = append(comps, f.submodules())
+		case "worktree":
+			comps = append(comps, f.worktree())
+		case "repo":
+			comps = append(comps, f.repo())
+		case "path":
+			comps = append(comps, f.path())
+		case "repo-flags":
+			comps = append(comps, f.repoFlags())
+		case "lfs":
+			comps = append(comps, f.lfs())
+		case "identity":
+			comps = append(comps, f.identity())
 		default:
 			sb.WriteString(joinComps())
 			sb.WriteString(f.Styles.Clear)
@@ -235,7 +750,7 @@ func (f *Formater) specialState() string {
 	case gitstatus.Bisecting:
 		s += fmt.Sprintf("%s[bisect] ", f.Styles.State)
 	case gitstatus.Default:
-		s += fmt.Sprintf("%s%s", f.Styles.Branch, f.Symbols.Branch)
+		s += fmt.Sprintf("%s%s", f.branchStyle(), f.Symbols.Branch)
 	}
 
 	s += f.currentRef()
@@ -244,30 +759,155 @@ func (f *Formater) specialState() string {
 
 func (f *Formater) remoteBranch() string {
 	if f.st.RemoteBranch == "" {
-		return ""
+		return f.noUpstream()
 	}
 
 	s := f.Styles.Clear
 
-	branch := truncate(f.st.RemoteBranch, f.Options.Ellipsis, f.Options.BranchMaxLen, f.Options.BranchTrim)
+	branch := f.truncateComp("remote-branch", f.aliasRemote(f.st.RemoteBranch), f.Options.BranchMaxLen, f.Options.BranchTrim)
+	if f.upstreamGone() {
+		return s + fmt.Sprintf("%s%s %s", f.Styles.Gone, branch, f.Symbols.Gone)
+	}
+
+	// Handle 'remote collapse'
+	if remote, ok := f.sameNameRemote(); ok {
+		switch f.Options.RemoteCollapse {
+		case collapseRemote:
+			branch = remote
+			if alias, ok := f.Options.RemoteAliases[remote]; ok {
+				branch = alias
+			}
+		case collapseSymbol:
+			branch = f.Symbols.RemoteSame
+		case collapseHide:
+			return ""
+		}
+	}
+
 	s += fmt.Sprintf("%s%s", f.Styles.Remote, branch)
 	return s
 }
 
+// sameNameRemote returns the remote of the upstream branch if the upstream
+// branch has the same name as the local branch, that is if it's exactly
+// <remote>/<local branch>. It's only looked up if remote_collapse is set.
+func (f *Formater) sameNameRemote() (string, bool) {
+	if f.Options.RemoteCollapse == "" || f.Options.RemoteCollapse == collapseNone || f.st.LocalBranch == "" {
+		return "", false
+	}
+
+	remote, err := f.Repo.UpstreamRemote(f.st.LocalBranch)
+	if err != nil || remote == "" || f.st.RemoteBranch != remote+"/"+f.st.LocalBranch {
+		return "", false
+	}
+	return remote, true
+}
+
+// aliasRemote replaces the remote name at the start of the given remote
+// branch with its alias, as defined by the remote_aliases option.
+func (f *Formater) aliasRemote(ref string) string {
+	remote := ""
+	for name := range f.Options.RemoteAliases {
+		// Remote names can contain slashes, so pick the longest match.
+		if strings.HasPrefix(ref, name+"/") && len(name) > len(remote) {
+			remote = name
+		}
+	}
+
+	if remote == "" {
+		return ref
+	}
+	return f.Options.RemoteAliases[remote] + ref[len(remote):]
+}
+
+// noUpstream returns the no_upstream symbol, if the current branch has no
+// upstream branch, optionally followed by the number of commits that are not
+// on any remote branch.
+func (f *Formater) noUpstream() string {
+	if f.Symbols.NoUpstream == "" || f.st.IsDetached || f.st.LocalBranch == "" {
+		return ""
+	}
+
+	s := f.Styles.Clear + f.Styles.NoUpstream + f.Symbols.NoUpstream
+	if f.Options.NoUpstreamCount {
+		if n, err := f.Repo.UnpublishedCommits(); err == nil && n != 0 {
+			s += fmt.Sprintf("%d", n)
+		}
+	}
+	return s
+}
+
+// upstreamGone reports whether the upstream branch doesn't exist anymore. An
+// empty 'gone' symbol disables the detection.
+func (f *Formater) upstreamGone() bool {
+	// A gone upstream has no ahead/behind counts.
+	if f.Symbols.Gone == "" || f.st.AheadCount != 0 || f.st.BehindCount != 0 {
+		return false
+	}
+
+	gone, err := f.Repo.UpstreamGone(f.st.LocalBranch)
+	return err == nil && gone
+}
+
 func (f *Formater) divergence() string {
-	if f.st.BehindCount == 0 && f.st.AheadCount == 0 {
+	return f.formatDivergence(f.Styles.Divergence, f.Symbols.Ahead, f.Symbols.Behind, f.st.AheadCount, f.st.BehindCount)
+}
+
+func (f *Formater) baseDivergence() string {
+	d, err := f.Repo.BaseDivergence(f.Options.BaseBranch)
+	if err != nil {
+		return ""
+	}
+
+	return f.formatDivergence(f.Styles.BaseDivergence, f.Symbols.BaseAhead, f.Symbols.BaseBehind, d.Ahead, d.Behind)
+}
+
+// push returns the divergence with the push branch, if it's not the upstream
+// branch, in which case push-branch and push-divergence would just repeat
+// remote-branch and divergence.
+func (f *Formater) push() (git.Divergence, bool) {
+	d, err := f.cache.push.get(f.Repo.PushDivergence)
+	if err != nil || d.Ref == "" || d.Ref == f.st.RemoteBranch {
+		return git.Divergence{}, false
+	}
+	return d, true
+}
+
+func (f *Formater) pushBranch() string {
+	d, ok := f.push()
+	if !ok {
+		return ""
+	}
+
+	branch := f.truncateComp("push-branch", f.aliasRemote(d.Ref), f.Options.BranchMaxLen, f.Options.BranchTrim)
+	return f.Styles.Clear + f.Styles.Push + branch
+}
+
+func (f *Formater) pushDivergence() string {
+	d, ok := f.push()
+	if !ok {
+		return ""
+	}
+
+	return f.formatDivergence(f.Styles.PushDivergence, f.Symbols.PushAhead, f.Symbols.PushBehind, d.Ahead, d.Behind)
+}
+
+// formatDivergence formats ahead and behind counts, honoring the
+// swap_divergence and divergence_space options.
+func (f *Formater) formatDivergence(style, aheadSym, behindSym string, aheadCount, behindCount int) string {
+	if behindCount == 0 && aheadCount == 0 {
 		return ""
 	}
 
 	behind := ""
 	ahead := ""
-	s := f.Styles.Clear + f.Styles.Divergence
-	if f.st.BehindCount != 0 {
-		behind = fmt.Sprintf("%s%d", f.Symbols.Behind, f.st.BehindCount)
+	s := f.Styles.Clear + style
+	if behindCount != 0 {
+		behind = fmt.Sprintf("%s%d", behindSym, behindCount)
 	}
 
-	if f.st.AheadCount != 0 {
-		ahead = fmt.Sprintf("%s%d", f.Symbols.Ahead, f.st.AheadCount)
+	if aheadCount != 0 {
+		ahead = fmt.Sprintf("%s%d", aheadSym, aheadCount)
 	}
 
 	// Handle 'swap divergence'
@@ -291,8 +931,46 @@ func (f *Formater) currentRef() string {
 		return fmt.Sprintf("%s%s%s%s", f.Styles.Clear, f.Styles.Branch, f.Symbols.HashPrefix, f.st.HEAD)
 	}
 
-	branch := truncate(f.st.LocalBranch, f.Options.Ellipsis, f.Options.BranchMaxLen, f.Options.BranchTrim)
-	return fmt.Sprintf("%s%s%s", f.Styles.Clear, f.Styles.Branch, branch)
+	branch := f.truncateComp("branch", f.st.LocalBranch, f.Options.BranchMaxLen, f.Options.BranchTrim)
+	s := fmt.Sprintf("%s%s%s", f.Styles.Clear, f.branchStyle(), branch)
+	if !f.isProtected() && !f.Options.BranchPolicy.allows(f.st.LocalBranch) {
+		s += f.Symbols.BranchPolicy
+	}
+	return s
+}
+
+// branchStyle returns the style of the local branch, which is branch_protected
+// for protected branches, or branch_protected_alert if the working tree is
+// dirty or has commits to push, or branch_policy if it violates the branch
+// policy.
+func (f *Formater) branchStyle() string {
+	switch {
+	case f.st.IsDetached:
+		return f.Styles.Branch
+	case f.isProtected():
+		if !f.st.IsClean || f.st.AheadCount != 0 {
+			return f.Styles.BranchProtectedAlert
+		}
+		return f.Styles.BranchProtected
+	case !f.Options.BranchPolicy.allows(f.st.LocalBranch):
+		return f.Styles.BranchPolicy
+	}
+	return f.Styles.Branch
+}
+
+// isProtected reports whether the local branch matches one of the
+// protected_branches glob patterns. Protected branches are exempt from the
+// branch policy.
+func (f *Formater) isProtected() bool {
+	if f.st.IsDetached || f.st.LocalBranch == "" {
+		return false
+	}
+	for _, pattern := range f.Options.ProtectedBranches {
+		if ok, _ := path.Match(pattern, f.st.LocalBranch); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // formatFlag formats a flag with or without count based on the flags_without_count option
@@ -305,9 +983,9 @@ func (f *Formater) formatFlag(style, symbol string, count int) string {
 
 func (f *Formater) flags() string {
 	var flags []string
-	if f.st.IsClean {
+	if f.st.IsClean && !f.UntrackedSkipped {
 		if f.st.NumStashed != 0 && f.Symbols.Stashed != "" {
-			flags = append(flags, f.formatFlag(f.Styles.Stashed, f.Symbols.Stashed, f.st.NumStashed))
+			flags = append(flags, f.stashed())
 		}
 
 		if !f.Options.HideClean && f.Symbols.Clean != "" {
@@ -331,11 +1009,20 @@ func (f *Formater) flags() string {
 		flags = append(flags, f.formatFlag(f.Styles.Modified, f.Symbols.Modified, f.st.NumModified))
 	}
 
+	flags = append(flags, f.changesFlags()...)
+
 	if f.st.NumStashed != 0 && f.Symbols.Stashed != "" {
-		flags = append(flags, f.formatFlag(f.Styles.Stashed, f.Symbols.Stashed, f.st.NumStashed))
+		flags = append(flags, f.stashed())
 	}
 
-	if f.st.NumUntracked != 0 && f.Symbols.Untracked != "" {
+	switch {
+	case f.UntrackedSkipped && f.Symbols.Untracked != "":
+		if f.Options.FlagsWithoutCount {
+			flags = append(flags, f.Styles.Untracked+f.Symbols.Untracked)
+		} else {
+			flags = append(flags, f.Styles.Untracked+f.Symbols.Untracked+"?")
+		}
+	case f.st.NumUntracked != 0 && f.Symbols.Untracked != "":
 		flags = append(flags, f.formatFlag(f.Styles.Untracked, f.Symbols.Untracked, f.st.NumUntracked))
 	}
 
@@ -346,15 +1033,126 @@ func (f *Formater) flags() string {
 	return ""
 }
 
+// stashed returns the stashed flag. With the stash_branch_count option, the
+// count is the number of stash entries created on the current branch followed
+// by the total count, like 1/3, and with the stash_age option, the age of the
+// newest or oldest stash entry is shown after it.
+func (f *Formater) stashed() string {
+	style := f.Styles.Stashed
+	count := strconv.Itoa(f.st.NumStashed)
+	age := ""
+
+	needStashes := f.Options.StashBranchCount || f.Options.StashAgeThreshold > 0 ||
+		f.Options.StashAge == stashAgeNewest || f.Options.StashAge == stashAgeOldest
+	if needStashes {
+		stashes, err := f.Repo.Stashes()
+		if err == nil && len(stashes) != 0 {
+			if f.Options.StashBranchCount {
+				n := 0
+				for _, st := range stashes {
+					if !f.st.IsDetached && st.Branch == f.st.LocalBranch {
+						n++
+					}
+				}
+				count = fmt.Sprintf("%d/%d", n, f.st.NumStashed)
+			}
+
+			// Stash entries are listed from the newest to the oldest.
+			newest := max(timeNow().Sub(stashes[0].Time), 0)
+			oldest := max(timeNow().Sub(stashes[len(stashes)-1].Time), 0)
+			if f.Options.StashAgeThreshold > 0 && oldest > f.Options.StashAgeThreshold {
+				style = f.Styles.StashedOld
+			}
+
+			switch f.Options.StashAge {
+			case stashAgeNewest:
+				age = formatAge(newest)
+			case stashAgeOldest:
+				age = formatAge(oldest)
+			}
+		}
+	}
+
+	if f.Options.FlagsWithoutCount {
+		return style + f.Symbols.Stashed + age
+	}
+	if age != "" {
+		age = " " + age
+	}
+	return style + f.Symbols.Stashed + count + age
+}
+
+// changesFlags returns the flags for added, deleted, renamed and type changed
+// files. Counting them requires an additional call to git status, so it's
+// only done if at least one of their symbols is set.
+func (f *Formater) changesFlags() []string {
+	if f.Symbols.Added == "" && f.Symbols.Deleted == "" && f.Symbols.Renamed == "" && f.Symbols.TypeChanged == "" {
+		return nil
+	}
+
+	c, err := f.Repo.Changes()
+	if err != nil {
+		return nil
+	}
+
+	var flags []string
+	if c.Added != 0 && f.Symbols.Added != "" {
+		flags = append(flags, f.formatFlag(f.Styles.Added, f.Symbols.Added, c.Added))
+	}
+
+	if c.Deleted != 0 && f.Symbols.Deleted != "" {
+		flags = append(flags, f.formatFlag(f.Styles.Deleted, f.Symbols.Deleted, c.Deleted))
+	}
+
+	if c.Renamed != 0 && f.Symbols.Renamed != "" {
+		flags = append(flags, f.formatFlag(f.Styles.Renamed, f.Symbols.Renamed, c.Renamed))
+	}
+
+	if c.TypeChanged != 0 && f.Symbols.TypeChanged != "" {
+		flags = append(flags, f.formatFlag(f.Styles.TypeChanged, f.Symbols.TypeChanged, c.TypeChanged))
+	}
+
+	return flags
+}
+
 func (f *Formater) stats() string {
+	return f.formatStats(
+		f.Styles.Insertions, f.Symbols.Insertions, f.st.Insertions,
+		f.Styles.Deletions, f.Symbols.Deletions, f.st.Deletions)
+}
+
+func (f *Formater) stagedStats() string {
+	st, err := f.Repo.Stats(true)
+	if err != nil {
+		return ""
+	}
+
+	return f.formatStats(
+		f.Styles.StagedInsertions, f.Symbols.StagedInsertions, st.Insertions,
+		f.Styles.StagedDeletions, f.Symbols.StagedDeletions, st.Deletions)
+}
+
+func (f *Formater) unstagedStats() string {
+	st, err := f.Repo.Stats(false)
+	if err != nil {
+		return ""
+	}
+
+	return f.formatStats(
+		f.Styles.UnstagedInsertions, f.Symbols.UnstagedInsertions, st.Insertions,
+		f.Styles.UnstagedDeletions, f.Symbols.UnstagedDeletions, st.Deletions)
+}
+
+// formatStats formats insertions and deletions counts.
+func (f *Formater) formatStats(insStyle, insSymbol string, insertions int, delStyle, delSymbol string, deletions int) string {
 	stats := make([]string, 0, 2)
 
-	if f.st.Insertions != 0 {
-		stats = append(stats, fmt.Sprintf("%s%s%d", f.Styles.Insertions, f.Symbols.Insertions, f.st.Insertions))
+	if insertions != 0 {
+		stats = append(stats, fmt.Sprintf("%s%s%d", insStyle, insSymbol, insertions))
 	}
 
-	if f.st.Deletions != 0 {
-		stats = append(stats, fmt.Sprintf("%s%s%d", f.Styles.Deletions, f.Symbols.Deletions, f.st.Deletions))
+	if deletions != 0 {
+		stats = append(stats, fmt.Sprintf("%s%s%d", delStyle, delSymbol, deletions))
 	}
 
 	if len(stats) == 0 {
@@ -363,3 +1161,245 @@ func (f *Formater) stats() string {
 
 	return f.Styles.Clear + strings.Join(stats, " ")
 }
+
+func (f *Formater) tag() string {
+	tags, err := f.Repo.Tags()
+	if err != nil {
+		return ""
+	}
+
+	s := f.Styles.Clear + f.Styles.Tag + f.Symbols.Tag
+	switch {
+	case len(tags.AtHEAD) != 0:
+		return s + strings.Join(tags.AtHEAD, ",")
+	case tags.Latest != "":
+		return fmt.Sprintf("%s%s+%d", s, tags.Latest, tags.Distance)
+	}
+
+	return ""
+}
+
+func (f *Formater) commitAge() string {
+	c, err := f.cache.lastCommit.get(f.Repo.LastCommit)
+	if err != nil {
+		return ""
+	}
+
+	age := max(timeNow().Sub(c.Time), 0)
+	style := f.Styles.CommitAge
+	if f.Options.CommitAgeThreshold > 0 && age > f.Options.CommitAgeThreshold {
+		style = f.Styles.CommitAgeOld
+	}
+
+	return f.Styles.Clear + style + formatAge(age)
+}
+
+func (f *Formater) commitSubject() string {
+	c, err := f.cache.lastCommit.get(f.Repo.LastCommit)
+	if err != nil || c.Subject == "" {
+		return ""
+	}
+
+	subject := f.truncateComp("commit-subject", c.Subject, f.Options.CommitSubjectMaxLen, dirRight)
+	return f.Styles.Clear + f.Styles.CommitSubject + subject
+}
+
+func (f *Formater) fetchAge() string {
+	t, err := f.Repo.LastFetch()
+	if err != nil || t.IsZero() {
+		return ""
+	}
+
+	age := max(timeNow().Sub(t), 0)
+	style := f.Styles.FetchAge
+	if f.Options.FetchAgeThreshold > 0 && age > f.Options.FetchAgeThreshold {
+		style = f.Styles.FetchAgeOld
+	}
+
+	return f.Styles.Clear + style + formatAge(age)
+}
+
+func (f *Formater) ignored() string {
+	ign, err := f.Repo.Ignored(f.Options.IgnoredSize)
+	if err != nil || ign.Count == 0 {
+		return ""
+	}
+
+	style := f.Styles.Ignored
+	if f.Options.IgnoredThreshold > 0 && ign.Count > f.Options.IgnoredThreshold ||
+		f.Options.IgnoredSizeThreshold > 0 && ign.Size > int64(f.Options.IgnoredSizeThreshold) {
+		style = f.Styles.IgnoredAlert
+	}
+
+	s := fmt.Sprintf("%s%s%s%d", f.Styles.Clear, style, f.Symbols.Ignored, ign.Count)
+	if f.Options.IgnoredSize {
+		s += " " + formatSize(ign.Size)
+	}
+	return s
+}
+
+func (f *Formater) submodules() string {
+	sm, err := f.Repo.Submodules()
+	if err != nil {
+		return ""
+	}
+
+	var s string
+	if sm.OutOfSync != 0 && f.Symbols.SubmoduleOutOfSync != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.SubmoduleOutOfSync, f.Symbols.SubmoduleOutOfSync, sm.OutOfSync)
+	}
+	if sm.Dirty != 0 && f.Symbols.SubmoduleDirty != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.SubmoduleDirty, f.Symbols.SubmoduleDirty, sm.Dirty)
+	}
+	if sm.Uninitialized != 0 && f.Symbols.SubmoduleUninitialized != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.SubmoduleUninitialized, f.Symbols.SubmoduleUninitialized, sm.Uninitialized)
+	}
+
+	if s == "" {
+		return ""
+	}
+	return f.Styles.Clear + s
+}
+
+// worktree shows the name of the current worktree and the number of
+// worktrees, unless the repository has a single one.
+func (f *Formater) worktree() string {
+	wt, err := f.Repo.Worktree()
+	if err != nil || wt.Count < 2 {
+		return ""
+	}
+
+	name := wt.Name
+	if name == "" {
+		name = "main"
+	}
+
+	s := fmt.Sprintf("%s%s%s%s·%d", f.Styles.Clear, f.Styles.Worktree, f.Symbols.Worktree, name, wt.Count)
+	if wt.Locked && f.Symbols.WorktreeLocked != "" {
+		s += " " + f.Styles.WorktreeLocked + f.Symbols.WorktreeLocked
+	}
+	if wt.Prunable && f.Symbols.WorktreePrunable != "" {
+		s += " " + f.Styles.WorktreePrunable + f.Symbols.WorktreePrunable
+	}
+	return s
+}
+
+// repo shows the name of the repository, which is the name of its top-level
+// directory, or with the repo_from_remote option, the base name of its remote
+// URL, if any.
+func (f *Formater) repo() string {
+	name := ""
+	if f.Options.RepoFromRemote {
+		url, err := f.Repo.RemoteURL()
+		if err != nil {
+			return ""
+		}
+		name = urlBase(url)
+	}
+
+	if name == "" {
+		loc, err := f.cache.location.get(f.Repo.Location)
+		if err != nil {
+			return ""
+		}
+		name = filepath.Base(loc.TopLevel)
+	}
+
+	name = f.truncateComp("repo", name, 0, dirRight)
+	return f.Styles.Clear + f.Styles.Repo + name
+}
+
+// urlBase returns the last element of a remote URL, without the .git suffix,
+// for example gitmux for git@github.com:arl/gitmux.git.
+func urlBase(url string) string {
+	url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+	if i := strings.LastIndexAny(url, "/:"); i != -1 {
+		url = url[i+1:]
+	}
+	return url
+}
+
+// path shows the path of the current directory relative to the repository
+// top-level directory, unless it's the top-level directory.
+func (f *Formater) path() string {
+	loc, err := f.cache.location.get(f.Repo.Location)
+	if err != nil || loc.Prefix == "" {
+		return ""
+	}
+
+	path := f.truncateComp("path", loc.Prefix, 0, dirLeft)
+	return f.Styles.Clear + f.Styles.Path + path
+}
+
+func (f *Formater) repoFlags() string {
+	rf, err := f.Repo.RepoFlags()
+	if err != nil {
+		return ""
+	}
+
+	var flags []string
+	if rf.Shallow && f.Symbols.Shallow != "" {
+		flags = append(flags, f.Styles.Shallow+f.Symbols.Shallow)
+	}
+	if rf.Partial && f.Symbols.Partial != "" {
+		flags = append(flags, f.Styles.Partial+f.Symbols.Partial)
+	}
+	if rf.Sparse && f.Symbols.Sparse != "" {
+		flags = append(flags, f.Styles.Sparse+f.Symbols.Sparse)
+	}
+
+	if len(flags) == 0 {
+		return ""
+	}
+	return f.Styles.Clear + strings.Join(flags, " ")
+}
+
+func (f *Formater) lfs() string {
+	lfs, err := f.Repo.LFS()
+	if err != nil {
+		return ""
+	}
+
+	var s string
+	if lfs.Pointers != 0 && f.Symbols.LFSPointers != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.LFSPointers, f.Symbols.LFSPointers, lfs.Pointers)
+	}
+	if lfs.Unpushed != 0 && f.Symbols.LFSUnpushed != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.LFSUnpushed, f.Symbols.LFSUnpushed, lfs.Unpushed)
+	}
+	if lfs.Locks != 0 && f.Symbols.LFSLocks != "" {
+		s += fmt.Sprintf("%s%s%d", f.Styles.LFSLocks, f.Symbols.LFSLocks, lfs.Locks)
+	}
+
+	if s == "" {
+		return ""
+	}
+	return f.Styles.Clear + s
+}
+
+// identity shows the email commits are created with, or its alias as defined
+// by the identity_aliases option, followed by the no_signing_key symbol if
+// commits are signed while no signing key is set.
+func (f *Formater) identity() string {
+	id, err := f.Repo.Identity()
+	if err != nil {
+		return ""
+	}
+
+	var comps []string
+	if id.Email != "" {
+		name := id.Email
+		if alias, ok := f.Options.IdentityAliases[id.Email]; ok {
+			name = alias
+		}
+		comps = append(comps, f.Styles.Identity+f.Symbols.Identity+name)
+	}
+	if id.NoSigningKey && f.Symbols.NoSigningKey != "" {
+		comps = append(comps, f.Styles.NoSigningKey+f.Symbols.NoSigningKey)
+	}
+
+	if len(comps) == 0 {
+		return ""
+	}
+	return f.Styles.Clear + strings.Join(comps, " ")
+}
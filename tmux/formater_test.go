@@ -1,12 +1,107 @@
 package tmux
 
 import (
+	"errors"
 	"io"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/arl/gitstatus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/arl/gitmux/git"
 )
 
+// fakeRepo is a Repo returning predefined values.
+type fakeRepo struct {
+	tags        git.Tags
+	commit      git.Commit
+	lastFetch   time.Time
+	base        git.Divergence
+	push        git.Divergence
+	gone        bool
+	upstream    string
+	unpublished int
+	changes     git.Changes
+	staged      git.Stats
+	unstaged    git.Stats
+	ignored     git.Ignored
+	submodules  git.Submodules
+	worktree    git.Worktree
+	location    git.Location
+	remoteURL   string
+	head        git.Head
+	repoFlags   git.RepoFlags
+	lfs         git.LFS
+	stashes     []git.Stash
+	identity    git.Identity
+	err         error
+}
+
+func (r *fakeRepo) Tags() (git.Tags, error)                      { return r.tags, r.err }
+func (r *fakeRepo) LastCommit() (git.Commit, error)              { return r.commit, r.err }
+func (r *fakeRepo) LastFetch() (time.Time, error)                { return r.lastFetch, r.err }
+func (r *fakeRepo) PushDivergence() (git.Divergence, error)      { return r.push, r.err }
+func (r *fakeRepo) UpstreamGone(branch string) (bool, error)     { return r.gone, r.err }
+func (r *fakeRepo) UnpublishedCommits() (int, error)             { return r.unpublished, r.err }
+func (r *fakeRepo) UpstreamRemote(branch string) (string, error) { return r.upstream, r.err }
+func (r *fakeRepo) Changes() (git.Changes, error)                { return r.changes, r.err }
+func (r *fakeRepo) Submodules() (git.Submodules, error)          { return r.submodules, r.err }
+func (r *fakeRepo) Worktree() (git.Worktree, error)              { return r.worktree, r.err }
+func (r *fakeRepo) Location() (git.Location, error)              { return r.location, r.err }
+func (r *fakeRepo) RemoteURL() (string, error)                   { return r.remoteURL, r.err }
+func (r *fakeRepo) Head() (git.Head, error)                      { return r.head, r.err }
+func (r *fakeRepo) RepoFlags() (git.RepoFlags, error)            { return r.repoFlags, r.err }
+func (r *fakeRepo) LFS() (git.LFS, error)                        { return r.lfs, r.err }
+func (r *fakeRepo) Stashes() ([]git.Stash, error)                { return r.stashes, r.err }
+func (r *fakeRepo) Identity() (git.Identity, error)              { return r.identity, r.err }
+
+func (r *fakeRepo) Stats(staged bool) (git.Stats, error) {
+	if staged {
+		return r.staged, r.err
+	}
+	return r.unstaged, r.err
+}
+
+func (r *fakeRepo) Ignored(size bool) (git.Ignored, error) {
+	ign := r.ignored
+	if !size {
+		ign.Size = 0
+	}
+	return ign, r.err
+}
+
+func (r *fakeRepo) BaseDivergence(base string) (git.Divergence, error) {
+	if base != "" && base != r.base.Ref {
+		return git.Divergence{}, errors.New("unknown base branch")
+	}
+	return r.base, r.err
+}
+
+// countingRepo counts the calls of the Repo methods which results are shared
+// by several components.
+type countingRepo struct {
+	*fakeRepo
+	calls map[string]int
+}
+
+func (r *countingRepo) PushDivergence() (git.Divergence, error) {
+	r.calls["PushDivergence"]++
+	return r.fakeRepo.PushDivergence()
+}
+
+func (r *countingRepo) LastCommit() (git.Commit, error) {
+	r.calls["LastCommit"]++
+	return r.fakeRepo.LastCommit()
+}
+
+func (r *countingRepo) Location() (git.Location, error) {
+	r.calls["Location"]++
+	return r.fakeRepo.Location()
+}
+
 func TestFlags(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -104,6 +199,190 @@ func TestFlags(t *testing.T) {
 	}
 }
 
+func TestFlagsUntrackedSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		options options
+		st      *gitstatus.Status
+		want    string
+	}{
+		{
+			name: "clean",
+			st: &gitstatus.Status{
+				IsClean: true,
+			},
+			want: "StyleClear" + "StyleUntrackedSymbolUntracked?",
+		},
+		{
+			name: "modified",
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{
+					NumModified: 2,
+				},
+			},
+			want: "StyleClear" + "StyleModSymbolMod2 StyleUntrackedSymbolUntracked?",
+		},
+		{
+			name:    "without count",
+			options: options{FlagsWithoutCount: true},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{
+					NumModified: 2,
+				},
+			},
+			want: "StyleClear" + "StyleModSymbolMod StyleUntrackedSymbolUntracked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:     "StyleClear",
+						Clean:     "StyleClean",
+						Modified:  "StyleMod",
+						Untracked: "StyleUntracked",
+					},
+					Symbols: symbols{
+						Clean:     "SymbolClean",
+						Modified:  "SymbolMod",
+						Untracked: "SymbolUntracked",
+					},
+					Options: tt.options,
+				},
+				UntrackedSkipped: true,
+				st:               tt.st,
+			}
+
+			compareStrings(t, tt.want, f.flags())
+		})
+	}
+}
+
+func Test_untrackedMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    untrackedMode
+		wantErr bool
+	}{
+		{in: "all", want: untrackedAll},
+		{in: "normal", want: untrackedNormal},
+		{in: "no", want: untrackedNo},
+		{in: "yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got untrackedMode
+			err := yaml.Unmarshal([]byte(tt.in), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbols symbols
+		options options
+		repo    *fakeRepo
+		st      *gitstatus.Status
+		want    string
+	}{
+		{
+			name: "disabled",
+			symbols: symbols{
+				Modified: "SymbolMod",
+			},
+			repo: &fakeRepo{
+				changes: git.Changes{Added: 1, Deleted: 2, Renamed: 3, TypeChanged: 4},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{NumModified: 2},
+			},
+			want: "StyleClear" + "StyleModSymbolMod2",
+		},
+		{
+			name: "all changes",
+			symbols: symbols{
+				Modified:    "SymbolMod",
+				Added:       "SymbolAdded",
+				Deleted:     "SymbolDeleted",
+				Renamed:     "SymbolRenamed",
+				TypeChanged: "SymbolTypeChanged",
+				Untracked:   "SymbolUntracked",
+			},
+			repo: &fakeRepo{
+				changes: git.Changes{Added: 1, Deleted: 2, Renamed: 3, TypeChanged: 4},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{NumModified: 2, NumUntracked: 5},
+			},
+			want: "StyleClear" + "StyleModSymbolMod2 " +
+				"StyleAddedSymbolAdded1 StyleDeletedSymbolDeleted2 StyleRenamedSymbolRenamed3 StyleTypeChangedSymbolTypeChanged4 " +
+				"StyleUntrackedSymbolUntracked5",
+		},
+		{
+			name: "some changes without count",
+			symbols: symbols{
+				Added:   "SymbolAdded",
+				Renamed: "SymbolRenamed",
+			},
+			options: options{FlagsWithoutCount: true},
+			repo: &fakeRepo{
+				changes: git.Changes{Added: 1, Deleted: 2, Renamed: 3},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{NumStaged: 6},
+			},
+			want: "StyleClear" + "StyleAddedSymbolAdded StyleRenamedSymbolRenamed",
+		},
+		{
+			name: "error",
+			symbols: symbols{
+				Staged: "SymbolStaged",
+				Added:  "SymbolAdded",
+			},
+			repo: &fakeRepo{
+				changes: git.Changes{Added: 1},
+				err:     errors.New("some error"),
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{NumStaged: 1},
+			},
+			want: "StyleClear" + "StyleStagedSymbolStaged1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:       "StyleClear",
+						Staged:      "StyleStaged",
+						Modified:    "StyleMod",
+						Untracked:   "StyleUntracked",
+						Added:       "StyleAdded",
+						Deleted:     "StyleDeleted",
+						Renamed:     "StyleRenamed",
+						TypeChanged: "StyleTypeChanged",
+					},
+					Symbols: tt.symbols,
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   tt.st,
+			}
+
+			compareStrings(t, tt.want, f.flags())
+		})
+	}
+}
+
 func TestFlagsWithoutCount(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -449,114 +728,527 @@ func TestDivergence(t *testing.T) {
 	}
 }
 
-func Test_truncate(t *testing.T) {
+func Test_baseDivergence(t *testing.T) {
 	tests := []struct {
-		s        string
-		max      int
-		ellipsis string
-		dir      direction
-		want     string
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
 	}{
-		/* trim right */
 		{
-			s:        "br",
-			ellipsis: "...",
-			max:      1,
-			dir:      dirRight,
-			want:     "b",
+			name: "error",
+			repo: &fakeRepo{
+				base: git.Divergence{Ref: "origin/main", Ahead: 1},
+				err:  errors.New("some error"),
+			},
+			want: "",
 		},
 		{
-			s:        "br",
-			ellipsis: "...",
-			max:      3,
-			dir:      dirRight,
-			want:     "br",
+			name: "no divergence",
+			repo: &fakeRepo{
+				base: git.Divergence{Ref: "origin/main"},
+			},
+			want: "",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      3,
-			dir:      dirRight,
-			want:     "...",
+			name: "diverged both ways",
+			repo: &fakeRepo{
+				base: git.Divergence{Ref: "origin/main", Ahead: 3, Behind: 12},
+			},
+			want: "StyleClear" + "StyleBaseDivergence" + "SymbolBaseBehind12SymbolBaseAhead3",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      15,
-			dir:      dirRight,
-			want:     "super-long-b...",
+			name:    "configured base branch",
+			options: options{BaseBranch: "upstream/develop"},
+			repo: &fakeRepo{
+				base: git.Divergence{Ref: "upstream/develop", Ahead: 3},
+			},
+			want: "StyleClear" + "StyleBaseDivergence" + "SymbolBaseAhead3",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      17,
-			dir:      dirRight,
-			want:     "super-long-branch",
+			name:    "swap divergence and divergence space",
+			options: options{SwapDivergence: true, DivergenceSpace: true},
+			repo: &fakeRepo{
+				base: git.Divergence{Ref: "origin/main", Ahead: 3, Behind: 12},
+			},
+			want: "StyleClear" + "StyleBaseDivergence" + "SymbolBaseAhead3 SymbolBaseBehind12",
 		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:          "StyleClear",
+						BaseDivergence: "StyleBaseDivergence",
+					},
+					Symbols: symbols{
+						BaseAhead:  "SymbolBaseAhead",
+						BaseBehind: "SymbolBaseBehind",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.want, f.baseDivergence())
+		})
+	}
+}
+
+func Test_remoteBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbols symbols
+		options options
+		repo    *fakeRepo
+		st      *gitstatus.Status
+		want    string
+	}{
 		{
-			s:        "super-long-branch",
-			ellipsis: "…",
-			max:      17,
-			dir:      dirRight,
-			want:     "super-long-branch",
+			name:    "no upstream symbol",
+			symbols: symbols{Gone: "SymbolGone"},
+			repo:    &fakeRepo{},
+			st:      &gitstatus.Status{},
+			want:    "",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "…",
-			max:      15,
-			dir:      dirRight,
-			want:     "super-long-bra…",
+			name:    "no upstream",
+			symbols: symbols{NoUpstream: "SymbolNoUpstream"},
+			repo:    &fakeRepo{unpublished: 3},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat"},
+			},
+			want: "StyleClear" + "StyleNoUpstream" + "SymbolNoUpstream",
 		},
 		{
-			s:        "长長的-树樹枝",
-			ellipsis: "...",
-			max:      6,
-			dir:      dirRight,
-			want:     "长長的...",
+			name:    "no upstream with count",
+			symbols: symbols{NoUpstream: "SymbolNoUpstream"},
+			options: options{NoUpstreamCount: true},
+			repo:    &fakeRepo{unpublished: 3},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat"},
+			},
+			want: "StyleClear" + "StyleNoUpstream" + "SymbolNoUpstream3",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      32,
-			dir:      dirRight,
-			want:     "super-long-branch",
+			name:    "no upstream with zero count",
+			symbols: symbols{NoUpstream: "SymbolNoUpstream"},
+			options: options{NoUpstreamCount: true},
+			repo:    &fakeRepo{},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat"},
+			},
+			want: "StyleClear" + "StyleNoUpstream" + "SymbolNoUpstream",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      0,
-			dir:      dirRight,
-			want:     "super-long-branch",
+			name:    "no upstream count error",
+			symbols: symbols{NoUpstream: "SymbolNoUpstream"},
+			options: options{NoUpstreamCount: true},
+			repo:    &fakeRepo{unpublished: 3, err: errors.New("some error")},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat"},
+			},
+			want: "StyleClear" + "StyleNoUpstream" + "SymbolNoUpstream",
 		},
 		{
-			s:        "super-long-branch",
-			ellipsis: "...",
-			max:      -1,
-			dir:      dirRight,
-			want:     "super-long-branch",
+			name:    "detached head has no upstream",
+			symbols: symbols{NoUpstream: "SymbolNoUpstream"},
+			repo:    &fakeRepo{},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{IsDetached: true},
+			},
+			want: "",
 		},
-
-		/* trim left */
 		{
-			s:        "br",
-			ellipsis: "...",
-			max:      1,
-			dir:      dirLeft,
-			want:     "r",
+			name:    "upstream exists",
+			symbols: symbols{Gone: "SymbolGone"},
+			repo:    &fakeRepo{},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feat",
 		},
 		{
-			s:        "br",
-			ellipsis: "",
-			max:      1,
-			dir:      dirLeft,
-			want:     "r",
+			name:    "upstream gone",
+			symbols: symbols{Gone: "SymbolGone"},
+			repo:    &fakeRepo{gone: true},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleGone" + "origin/feat SymbolGone",
 		},
 		{
-			s:        "br",
-			ellipsis: "...",
-			max:      3,
-			dir:      dirLeft,
-			want:     "br",
+			name:    "error",
+			symbols: symbols{Gone: "SymbolGone"},
+			repo:    &fakeRepo{gone: true, err: errors.New("some error")},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feat",
+		},
+		{
+			name: "gone detection disabled",
+			repo: &fakeRepo{gone: true},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feat",
+		},
+		{
+			name:    "remote collapse none",
+			options: options{RemoteCollapse: collapseNone},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feature/very-long-name", RemoteBranch: "origin/feature/very-long-name"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feature/very-long-name",
+		},
+		{
+			name:    "remote collapse remote",
+			options: options{RemoteCollapse: collapseRemote},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feature/very-long-name", RemoteBranch: "origin/feature/very-long-name"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin",
+		},
+		{
+			name:    "remote collapse remote with alias",
+			options: options{RemoteCollapse: collapseRemote, RemoteAliases: map[string]string{"origin": "AliasOrigin"}},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "AliasOrigin",
+		},
+		{
+			name:    "remote collapse symbol",
+			symbols: symbols{RemoteSame: "SymbolRemoteSame"},
+			options: options{RemoteCollapse: collapseSymbol},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "SymbolRemoteSame",
+		},
+		{
+			name:    "remote collapse hide",
+			options: options{RemoteCollapse: collapseHide},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "",
+		},
+		{
+			name:    "remote collapse with different branch names",
+			options: options{RemoteCollapse: collapseHide},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "upstream/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "upstream/main",
+		},
+		{
+			name:    "remote collapse with upstream ending with the local branch",
+			options: options{RemoteCollapse: collapseRemote},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "x/main", RemoteBranch: "origin/feature/x/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feature/x/main",
+		},
+		{
+			name:    "remote collapse hide with upstream ending with the local branch",
+			options: options{RemoteCollapse: collapseHide},
+			repo:    &fakeRepo{upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "x/main", RemoteBranch: "origin/feature/x/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feature/x/main",
+		},
+		{
+			name:    "remote collapse with slash in remote name",
+			options: options{RemoteCollapse: collapseRemote},
+			repo:    &fakeRepo{upstream: "team/origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "main", RemoteBranch: "team/origin/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "team/origin",
+		},
+		{
+			name:    "remote collapse error",
+			options: options{RemoteCollapse: collapseHide},
+			repo:    &fakeRepo{upstream: "origin", err: errors.New("some error")},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feat",
+		},
+		{
+			name:    "remote collapse hide doesn't hide gone upstream",
+			symbols: symbols{Gone: "SymbolGone"},
+			options: options{RemoteCollapse: collapseHide},
+			repo:    &fakeRepo{gone: true, upstream: "origin"},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat"},
+			},
+			want: "StyleClear" + "StyleGone" + "origin/feat SymbolGone",
+		},
+		{
+			name: "remote aliases",
+			options: options{RemoteAliases: map[string]string{
+				"upstream":     "AliasUpstream",
+				"upstream/foo": "AliasUpstreamFoo",
+			}},
+			repo: &fakeRepo{},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "upstream/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "AliasUpstream/main",
+		},
+		{
+			name: "remote aliases with slashes",
+			options: options{RemoteAliases: map[string]string{
+				"upstream":     "AliasUpstream",
+				"upstream/foo": "AliasUpstreamFoo",
+			}},
+			repo: &fakeRepo{},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "upstream/foo/main"},
+			},
+			want: "StyleClear" + "StyleRemote" + "AliasUpstreamFoo/main",
+		},
+		{
+			name:    "diverged upstream can't be gone",
+			symbols: symbols{Gone: "SymbolGone"},
+			repo:    &fakeRepo{gone: true},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{LocalBranch: "feat", RemoteBranch: "origin/feat", AheadCount: 1},
+			},
+			want: "StyleClear" + "StyleRemote" + "origin/feat",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:      "StyleClear",
+						Remote:     "StyleRemote",
+						Gone:       "StyleGone",
+						NoUpstream: "StyleNoUpstream",
+					},
+					Symbols: tt.symbols,
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   tt.st,
+			}
+
+			compareStrings(t, tt.want, f.remoteBranch())
+		})
+	}
+}
+
+func TestPush(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    options
+		repo       *fakeRepo
+		st         *gitstatus.Status
+		wantBranch string
+		wantDiv    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				push: git.Divergence{Ref: "fork/feature", Ahead: 1},
+				err:  errors.New("some error"),
+			},
+			st: &gitstatus.Status{},
+		},
+		{
+			name: "no push branch",
+			repo: &fakeRepo{},
+			st:   &gitstatus.Status{},
+		},
+		{
+			name: "push branch is upstream",
+			repo: &fakeRepo{
+				push: git.Divergence{Ref: "origin/main", Ahead: 1},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{RemoteBranch: "origin/main", AheadCount: 1},
+			},
+		},
+		{
+			name: "in sync with push branch",
+			repo: &fakeRepo{
+				push: git.Divergence{Ref: "fork/feature"},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{RemoteBranch: "origin/main", BehindCount: 3},
+			},
+			wantBranch: "StyleClear" + "StylePush" + "fork/feature",
+		},
+		{
+			name: "diverged from push branch",
+			repo: &fakeRepo{
+				push: git.Divergence{Ref: "fork/feature", Ahead: 2, Behind: 1},
+			},
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{RemoteBranch: "origin/main"},
+			},
+			wantBranch: "StyleClear" + "StylePush" + "fork/feature",
+			wantDiv:    "StyleClear" + "StylePushDivergence" + "SymbolPushBehind1SymbolPushAhead2",
+		},
+		{
+			name:    "truncated push branch",
+			options: options{BranchMaxLen: 8, BranchTrim: dirLeft, Ellipsis: "…"},
+			repo: &fakeRepo{
+				push: git.Divergence{Ref: "fork/feature", Ahead: 2},
+			},
+			st:         &gitstatus.Status{},
+			wantBranch: "StyleClear" + "StylePush" + "…feature",
+			wantDiv:    "StyleClear" + "StylePushDivergence" + "SymbolPushAhead2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:          "StyleClear",
+						Push:           "StylePush",
+						PushDivergence: "StylePushDivergence",
+					},
+					Symbols: symbols{
+						PushAhead:  "SymbolPushAhead",
+						PushBehind: "SymbolPushBehind",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   tt.st,
+			}
+
+			compareStrings(t, tt.wantBranch, f.pushBranch())
+			compareStrings(t, tt.wantDiv, f.pushDivergence())
+		})
+	}
+}
+
+func Test_truncate(t *testing.T) {
+	tests := []struct {
+		s        string
+		max      int
+		ellipsis string
+		dir      direction
+		want     string
+	}{
+		/* trim right */
+		{
+			s:        "br",
+			ellipsis: "...",
+			max:      1,
+			dir:      dirRight,
+			want:     "b",
+		},
+		{
+			s:        "br",
+			ellipsis: "...",
+			max:      3,
+			dir:      dirRight,
+			want:     "br",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      3,
+			dir:      dirRight,
+			want:     "...",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      15,
+			dir:      dirRight,
+			want:     "super-long-b...",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      17,
+			dir:      dirRight,
+			want:     "super-long-branch",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "…",
+			max:      17,
+			dir:      dirRight,
+			want:     "super-long-branch",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "…",
+			max:      15,
+			dir:      dirRight,
+			want:     "super-long-bra…",
+		},
+		{
+			s:        "长長的-树樹枝",
+			ellipsis: "...",
+			max:      6,
+			dir:      dirRight,
+			want:     "长長的...",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      32,
+			dir:      dirRight,
+			want:     "super-long-branch",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      0,
+			dir:      dirRight,
+			want:     "super-long-branch",
+		},
+		{
+			s:        "super-long-branch",
+			ellipsis: "...",
+			max:      -1,
+			dir:      dirRight,
+			want:     "super-long-branch",
+		},
+
+		/* trim left */
+		{
+			s:        "br",
+			ellipsis: "...",
+			max:      1,
+			dir:      dirLeft,
+			want:     "r",
+		},
+		{
+			s:        "br",
+			ellipsis: "",
+			max:      1,
+			dir:      dirLeft,
+			want:     "r",
+		},
+		{
+			s:        "br",
+			ellipsis: "...",
+			max:      3,
+			dir:      dirLeft,
+			want:     "br",
 		},
 		{
 			s:        "super-long-branch",
@@ -687,6 +1379,71 @@ func Test_truncate(t *testing.T) {
 	}
 }
 
+func Test_truncateComp(t *testing.T) {
+	const cfg = `
+options:
+    ellipsis: …
+    branch_max_len: 12
+    branch_trim: right
+    truncate:
+        remote-branch:
+            max: 10
+            dir: left
+            ellipsis: ".."
+        commit-subject:
+            max: 0
+        push-branch:
+            dir: center
+`
+	var c Config
+	if err := yaml.Unmarshal([]byte(cfg), &c); err != nil {
+		t.Fatalf("can't decode config: %v", err)
+	}
+
+	tests := []struct {
+		comp string
+		s    string
+		max  int
+		dir  direction
+		want string
+	}{
+		{
+			comp: "branch",
+			s:    "feature/very-long-name",
+			max:  c.Options.BranchMaxLen,
+			dir:  c.Options.BranchTrim,
+			want: "feature/ver…",
+		},
+		{
+			comp: "remote-branch",
+			s:    "origin/feature/very-long-name",
+			max:  c.Options.BranchMaxLen,
+			dir:  c.Options.BranchTrim,
+			want: "..ong-name",
+		},
+		{
+			comp: "push-branch",
+			s:    "fork/feature/very-long-name",
+			max:  c.Options.BranchMaxLen,
+			dir:  c.Options.BranchTrim,
+			want: "fork/…g-name",
+		},
+		{
+			comp: "commit-subject",
+			s:    "Add some very long commit subject",
+			max:  20,
+			dir:  dirRight,
+			want: "Add some very long commit subject",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.comp, func(t *testing.T) {
+			f := &Formater{Config: c}
+			compareStrings(t, tt.want, f.truncateComp(tt.comp, tt.s, tt.max, tt.dir))
+		})
+	}
+}
+
 func TestFormat(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -925,32 +1682,971 @@ func TestFormat(t *testing.T) {
 	}
 }
 
-func Test_stats(t *testing.T) {
+func TestFormatRepoCalls(t *testing.T) {
 	tests := []struct {
-		name                  string
-		layout                []string
-		insertions, deletions int
-		want                  string
+		layout []string
+		method string
 	}{
 		{
-			name: "nothing",
-			want: "",
+			layout: []string{"push-branch", "push-divergence"},
+			method: "PushDivergence",
 		},
 		{
-			name:       "insertions",
-			insertions: 12,
-			want:       "StyleClear" + "StyleInsertionsSymbolInsertions12",
+			layout: []string{"commit-age", "commit-subject"},
+			method: "LastCommit",
 		},
 		{
-			name:      "deletions",
-			deletions: 12,
+			layout: []string{"repo", "path"},
+			method: "Location",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			repo := &countingRepo{
+				fakeRepo: &fakeRepo{
+					push:     git.Divergence{Ref: "fork/feature", Ahead: 1},
+					commit:   git.Commit{Subject: "Add some file"},
+					location: git.Location{TopLevel: "/src/gitmux", Prefix: "tmux"},
+				},
+				calls: map[string]int{},
+			}
+			f := &Formater{
+				Config: Config{Layout: tt.layout},
+				Repo:   repo,
+			}
+
+			st := &gitstatus.Status{}
+			for range 2 {
+				if err := f.Format(io.Discard, st); err != nil {
+					t.Fatalf("Format error: %s", err)
+				}
+			}
+
+			if got := repo.calls[tt.method]; got != 2 {
+				t.Errorf("%s called %d times for 2 Format calls, want 2", tt.method, got)
+			}
+		})
+	}
+}
+
+func Test_stats(t *testing.T) {
+	tests := []struct {
+		name                  string
+		layout                []string
+		insertions, deletions int
+		want                  string
+	}{
+		{
+			name: "nothing",
+			want: "",
+		},
+		{
+			name:       "insertions",
+			insertions: 12,
+			want:       "StyleClear" + "StyleInsertionsSymbolInsertions12",
+		},
+		{
+			name:      "deletions",
+			deletions: 12,
 			want:      "StyleClear" + "StyleDeletionsSymbolDeletions12",
 		},
 		{
-			name:       "insertions and deletions",
-			insertions: 1,
-			deletions:  2,
-			want:       "StyleClear" + "StyleInsertionsSymbolInsertions1" + " " + "StyleDeletionsSymbolDeletions2",
+			name:       "insertions and deletions",
+			insertions: 1,
+			deletions:  2,
+			want:       "StyleClear" + "StyleInsertionsSymbolInsertions1" + " " + "StyleDeletionsSymbolDeletions2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:      "StyleClear",
+						Deletions:  "StyleDeletions",
+						Insertions: "StyleInsertions",
+					},
+					Symbols: symbols{
+						Deletions:  "SymbolDeletions",
+						Insertions: "SymbolInsertions",
+					},
+					Layout: []string{"stats"},
+				},
+				st: &gitstatus.Status{
+					Insertions: tt.insertions,
+					Deletions:  tt.deletions,
+				},
+			}
+
+			compareStrings(t, tt.want, f.stats())
+		})
+	}
+}
+
+func Test_tag(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *fakeRepo
+		want string
+	}{
+		{
+			name: "no tags",
+			repo: &fakeRepo{},
+			want: "",
+		},
+		{
+			name: "error",
+			repo: &fakeRepo{
+				tags: git.Tags{AtHEAD: []string{"v1.4.0"}},
+				err:  errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "tag at HEAD",
+			repo: &fakeRepo{
+				tags: git.Tags{AtHEAD: []string{"v1.4.0"}},
+			},
+			want: "StyleClear" + "StyleTagSymbolTag" + "v1.4.0",
+		},
+		{
+			name: "multiple tags at HEAD",
+			repo: &fakeRepo{
+				tags: git.Tags{AtHEAD: []string{"v1.4.0", "latest"}},
+			},
+			want: "StyleClear" + "StyleTagSymbolTag" + "v1.4.0,latest",
+		},
+		{
+			name: "commits since latest tag",
+			repo: &fakeRepo{
+				tags: git.Tags{Latest: "v1.4.0", Distance: 7},
+			},
+			want: "StyleClear" + "StyleTagSymbolTag" + "v1.4.0+7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles:  styles{Clear: "StyleClear", Tag: "StyleTag"},
+					Symbols: symbols{Tag: "SymbolTag"},
+					Layout:  []string{"tag"},
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.want, f.tag())
+		})
+	}
+}
+
+func Test_formatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 59 * time.Second, want: "59s"},
+		{d: 61 * time.Second, want: "1m"},
+		{d: 3*time.Hour + 59*time.Minute, want: "3h"},
+		{d: 2*24*time.Hour + 23*time.Hour, want: "2d"},
+		{d: 400 * 24 * time.Hour, want: "1y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			compareStrings(t, tt.want, formatAge(tt.d))
+		})
+	}
+}
+
+func Test_commitAge(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	defer func(old func() time.Time) { timeNow = old }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{err: errors.New("some error")},
+			want: "",
+		},
+		{
+			name: "no threshold",
+			repo: &fakeRepo{
+				commit: git.Commit{Time: now.Add(-30 * 24 * time.Hour)},
+			},
+			want: "StyleClear" + "StyleCommitAge" + "30d",
+		},
+		{
+			name:    "below threshold",
+			options: options{CommitAgeThreshold: 7 * 24 * time.Hour},
+			repo: &fakeRepo{
+				commit: git.Commit{Time: now.Add(-3 * time.Hour)},
+			},
+			want: "StyleClear" + "StyleCommitAge" + "3h",
+		},
+		{
+			name:    "above threshold",
+			options: options{CommitAgeThreshold: 7 * 24 * time.Hour},
+			repo: &fakeRepo{
+				commit: git.Commit{Time: now.Add(-8 * 24 * time.Hour)},
+			},
+			want: "StyleClear" + "StyleCommitAgeOld" + "8d",
+		},
+		{
+			name: "commit in the future",
+			repo: &fakeRepo{
+				commit: git.Commit{Time: now.Add(time.Hour)},
+			},
+			want: "StyleClear" + "StyleCommitAge" + "0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:        "StyleClear",
+						CommitAge:    "StyleCommitAge",
+						CommitAgeOld: "StyleCommitAgeOld",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.want, f.commitAge())
+		})
+	}
+}
+
+func Test_commitSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{err: errors.New("some error")},
+			want: "",
+		},
+		{
+			name: "empty subject",
+			repo: &fakeRepo{},
+			want: "",
+		},
+		{
+			name: "no max length",
+			repo: &fakeRepo{
+				commit: git.Commit{Subject: "Add some file"},
+			},
+			want: "StyleClear" + "StyleCommitSubject" + "Add some file",
+		},
+		{
+			name:    "truncated",
+			options: options{CommitSubjectMaxLen: 8, Ellipsis: "…"},
+			repo: &fakeRepo{
+				commit: git.Commit{Subject: "Add some file"},
+			},
+			want: "StyleClear" + "StyleCommitSubject" + "Add som…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:         "StyleClear",
+						CommitSubject: "StyleCommitSubject",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.want, f.commitSubject())
+		})
+	}
+}
+
+func Test_fetchAge(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	defer func(old func() time.Time) { timeNow = old }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{lastFetch: now, err: errors.New("some error")},
+			want: "",
+		},
+		{
+			name: "never fetched",
+			repo: &fakeRepo{},
+			want: "",
+		},
+		{
+			name:    "below threshold",
+			options: options{FetchAgeThreshold: time.Hour},
+			repo:    &fakeRepo{lastFetch: now.Add(-5 * time.Minute)},
+			want:    "StyleClear" + "StyleFetchAge" + "5m",
+		},
+		{
+			name:    "above threshold",
+			options: options{FetchAgeThreshold: time.Hour},
+			repo:    &fakeRepo{lastFetch: now.Add(-3 * time.Hour)},
+			want:    "StyleClear" + "StyleFetchAgeOld" + "3h",
+		},
+		{
+			name: "no threshold",
+			repo: &fakeRepo{lastFetch: now.Add(-3 * time.Hour)},
+			want: "StyleClear" + "StyleFetchAge" + "3h",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:       "StyleClear",
+						FetchAge:    "StyleFetchAge",
+						FetchAgeOld: "StyleFetchAgeOld",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.want, f.fetchAge())
+		})
+	}
+}
+
+func Test_stagedUnstagedStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		repo         *fakeRepo
+		wantStaged   string
+		wantUnstaged string
+	}{
+		{
+			name: "nothing",
+			repo: &fakeRepo{},
+		},
+		{
+			name: "error",
+			repo: &fakeRepo{
+				staged:   git.Stats{Insertions: 1},
+				unstaged: git.Stats{Insertions: 2},
+				err:      errors.New("some error"),
+			},
+		},
+		{
+			name: "staged only",
+			repo: &fakeRepo{
+				staged: git.Stats{Insertions: 12, Deletions: 3},
+			},
+			wantStaged: "StyleClear" + "StyleStagedInsSymbolStagedIns12" + " " + "StyleStagedDelSymbolStagedDel3",
+		},
+		{
+			name: "staged and unstaged",
+			repo: &fakeRepo{
+				staged:   git.Stats{Insertions: 12},
+				unstaged: git.Stats{Insertions: 1, Deletions: 5},
+			},
+			wantStaged:   "StyleClear" + "StyleStagedInsSymbolStagedIns12",
+			wantUnstaged: "StyleClear" + "StyleUnstagedInsSymbolUnstagedIns1" + " " + "StyleUnstagedDelSymbolUnstagedDel5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:              "StyleClear",
+						StagedInsertions:   "StyleStagedIns",
+						StagedDeletions:    "StyleStagedDel",
+						UnstagedInsertions: "StyleUnstagedIns",
+						UnstagedDeletions:  "StyleUnstagedDel",
+					},
+					Symbols: symbols{
+						StagedInsertions:   "SymbolStagedIns",
+						StagedDeletions:    "SymbolStagedDel",
+						UnstagedInsertions: "SymbolUnstagedIns",
+						UnstagedDeletions:  "SymbolUnstagedDel",
+					},
+				},
+				Repo: tt.repo,
+				st:   &gitstatus.Status{},
+			}
+
+			compareStrings(t, tt.wantStaged, f.stagedStats())
+			compareStrings(t, tt.wantUnstaged, f.unstagedStats())
+		})
+	}
+}
+
+func Test_fetchInterval(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    fetchInterval
+		wantErr bool
+	}{
+		{in: "5m", want: fetchInterval(5 * time.Minute)},
+		{in: "1h30m", want: fetchInterval(90 * time.Minute)},
+		{in: "0s", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "-5m", wantErr: true},
+		{in: "often", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got fetchInterval
+			err := yaml.Unmarshal([]byte(tt.in), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%q) = %v, want %v", tt.in, time.Duration(got), time.Duration(tt.want))
+			}
+		})
+	}
+}
+
+func Test_formatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0B"},
+		{n: 1023, want: "1023B"},
+		{n: 1024, want: "1K"},
+		{n: 3<<20 - 1, want: "2M"},
+		{n: 3 << 20, want: "3M"},
+		{n: 5<<30 + 512<<20, want: "5G"},
+		{n: 2048 << 40, want: "2048T"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			compareStrings(t, tt.want, formatSize(tt.n))
+		})
+	}
+}
+
+func Test_byteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    byteSize
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "1500", want: 1500},
+		{in: "10K", want: 10 << 10},
+		{in: "500M", want: 500 << 20},
+		{in: "2g", want: 2 << 30},
+		{in: "1T", want: 1 << 40},
+		{in: "M", wantErr: true},
+		{in: "-1G", wantErr: true},
+		{in: "1.5G", wantErr: true},
+		{in: "12X", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got byteSize
+			err := yaml.Unmarshal([]byte(tt.in), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ignored(t *testing.T) {
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 3},
+				err:     errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "no ignored paths",
+			repo: &fakeRepo{},
+			want: "",
+		},
+		{
+			name: "count",
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 3, Size: 5 << 20},
+			},
+			want: "StyleClear" + "StyleIgnored" + "SymbolIgnored3",
+		},
+		{
+			name:    "count and size",
+			options: options{IgnoredSize: true},
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 3, Size: 5 << 20},
+			},
+			want: "StyleClear" + "StyleIgnored" + "SymbolIgnored3 5M",
+		},
+		{
+			name:    "below thresholds",
+			options: options{IgnoredSize: true, IgnoredThreshold: 3, IgnoredSizeThreshold: 5 << 20},
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 3, Size: 5 << 20},
+			},
+			want: "StyleClear" + "StyleIgnored" + "SymbolIgnored3 5M",
+		},
+		{
+			name:    "count above threshold",
+			options: options{IgnoredThreshold: 2},
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 3},
+			},
+			want: "StyleClear" + "StyleIgnoredAlert" + "SymbolIgnored3",
+		},
+		{
+			name:    "size above threshold",
+			options: options{IgnoredSize: true, IgnoredSizeThreshold: 1 << 20},
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 1, Size: 2 << 30},
+			},
+			want: "StyleClear" + "StyleIgnoredAlert" + "SymbolIgnored1 2G",
+		},
+		{
+			name:    "size threshold without size",
+			options: options{IgnoredSizeThreshold: 1 << 20},
+			repo: &fakeRepo{
+				ignored: git.Ignored{Count: 1, Size: 2 << 30},
+			},
+			want: "StyleClear" + "StyleIgnored" + "SymbolIgnored1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:        "StyleClear",
+						Ignored:      "StyleIgnored",
+						IgnoredAlert: "StyleIgnoredAlert",
+					},
+					Symbols: symbols{
+						Ignored: "SymbolIgnored",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.ignored())
+		})
+	}
+}
+
+func Test_submodules(t *testing.T) {
+	allSymbols := symbols{
+		SubmoduleOutOfSync:     "SymbolOutOfSync",
+		SubmoduleDirty:         "SymbolDirty",
+		SubmoduleUninitialized: "SymbolUninit",
+	}
+
+	tests := []struct {
+		name    string
+		symbols symbols
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name:    "error",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				submodules: git.Submodules{OutOfSync: 1},
+				err:        errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name:    "in sync",
+			symbols: allSymbols,
+			repo:    &fakeRepo{},
+			want:    "",
+		},
+		{
+			name:    "all kinds",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				submodules: git.Submodules{OutOfSync: 2, Dirty: 1, Uninitialized: 3},
+			},
+			want: "StyleClear" + "StyleOutOfSyncSymbolOutOfSync2" + "StyleDirtySymbolDirty1" + "StyleUninitSymbolUninit3",
+		},
+		{
+			name:    "dirty only",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				submodules: git.Submodules{Dirty: 1},
+			},
+			want: "StyleClear" + "StyleDirtySymbolDirty1",
+		},
+		{
+			name: "empty symbol",
+			symbols: symbols{
+				SubmoduleOutOfSync: "SymbolOutOfSync",
+				SubmoduleDirty:     "SymbolDirty",
+			},
+			repo: &fakeRepo{
+				submodules: git.Submodules{Uninitialized: 3},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:                  "StyleClear",
+						SubmoduleOutOfSync:     "StyleOutOfSync",
+						SubmoduleDirty:         "StyleDirty",
+						SubmoduleUninitialized: "StyleUninit",
+					},
+					Symbols: tt.symbols,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.submodules())
+		})
+	}
+}
+
+func Test_worktree(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *fakeRepo
+		want string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				worktree: git.Worktree{Name: "feat", Count: 2},
+				err:      errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "single worktree",
+			repo: &fakeRepo{
+				worktree: git.Worktree{Count: 1},
+			},
+			want: "",
+		},
+		{
+			name: "main worktree",
+			repo: &fakeRepo{
+				worktree: git.Worktree{Count: 3},
+			},
+			want: "StyleClear" + "StyleWorktree" + "SymbolWorktree" + "main·3",
+		},
+		{
+			name: "linked worktree",
+			repo: &fakeRepo{
+				worktree: git.Worktree{Name: "feat", Count: 2},
+			},
+			want: "StyleClear" + "StyleWorktree" + "SymbolWorktree" + "feat·2",
+		},
+		{
+			name: "locked and prunable",
+			repo: &fakeRepo{
+				worktree: git.Worktree{Name: "feat", Count: 2, Locked: true, Prunable: true},
+			},
+			want: "StyleClear" + "StyleWorktree" + "SymbolWorktree" + "feat·2" +
+				" " + "StyleLocked" + "SymbolLocked" +
+				" " + "StylePrunable" + "SymbolPrunable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:            "StyleClear",
+						Worktree:         "StyleWorktree",
+						WorktreeLocked:   "StyleLocked",
+						WorktreePrunable: "StylePrunable",
+					},
+					Symbols: symbols{
+						Worktree:         "SymbolWorktree",
+						WorktreeLocked:   "SymbolLocked",
+						WorktreePrunable: "SymbolPrunable",
+					},
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.worktree())
+		})
+	}
+}
+
+func Test_urlBase(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://github.com/arl/gitmux.git", want: "gitmux"},
+		{url: "https://github.com/arl/gitmux", want: "gitmux"},
+		{url: "https://github.com/arl/gitmux/", want: "gitmux"},
+		{url: "git@github.com:arl/gitmux.git", want: "gitmux"},
+		{url: "host:gitmux.git", want: "gitmux"},
+		{url: "/srv/git/gitmux.git/", want: "gitmux"},
+		{url: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			compareStrings(t, tt.want, urlBase(tt.url))
+		})
+	}
+}
+
+func Test_repo(t *testing.T) {
+	maxLen := 4
+
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux"},
+				err:      errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "top-level directory",
+			repo: &fakeRepo{
+				location:  git.Location{TopLevel: "/src/gitmux"},
+				remoteURL: "git@github.com:arl/gitmux-fork.git",
+			},
+			want: "StyleClear" + "StyleRepo" + "gitmux",
+		},
+		{
+			name:    "remote",
+			options: options{RepoFromRemote: true},
+			repo: &fakeRepo{
+				location:  git.Location{TopLevel: "/src/gitmux"},
+				remoteURL: "git@github.com:arl/gitmux-fork.git",
+			},
+			want: "StyleClear" + "StyleRepo" + "gitmux-fork",
+		},
+		{
+			name:    "no remote",
+			options: options{RepoFromRemote: true},
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux"},
+			},
+			want: "StyleClear" + "StyleRepo" + "gitmux",
+		},
+		{
+			name: "truncated",
+			options: options{
+				Ellipsis: "…",
+				Truncate: map[string]truncateOptions{"repo": {Max: &maxLen}},
+			},
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux"},
+			},
+			want: "StyleClear" + "StyleRepo" + "git…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear: "StyleClear",
+						Repo:  "StyleRepo",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.repo())
+		})
+	}
+}
+
+func Test_path(t *testing.T) {
+	maxLen := 8
+
+	tests := []struct {
+		name    string
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux", Prefix: "tmux/"},
+				err:      errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "top-level directory",
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux"},
+			},
+			want: "",
+		},
+		{
+			name: "subdirectory",
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux", Prefix: "tmux/"},
+			},
+			want: "StyleClear" + "StylePath" + "tmux/",
+		},
+		{
+			name: "truncated",
+			options: options{
+				Ellipsis: "…",
+				Truncate: map[string]truncateOptions{"path": {Max: &maxLen}},
+			},
+			repo: &fakeRepo{
+				location: git.Location{TopLevel: "/src/gitmux", Prefix: "some/deep/path/"},
+			},
+			want: "StyleClear" + "StylePath" + "…p/path/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear: "StyleClear",
+						Path:  "StylePath",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.path())
+		})
+	}
+}
+
+func TestFormatNotARepo(t *testing.T) {
+	tests := []struct {
+		name   string
+		symbol string
+		want   string
+	}{
+		{
+			name: "empty symbol",
+			want: "",
+		},
+		{
+			name:   "symbol",
+			symbol: "no git",
+			want:   "StyleClear" + "StyleNotARepo" + "no git" + resetStyles,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:    "StyleClear",
+						NotARepo: "StyleNotARepo",
+					},
+					Symbols: symbols{
+						NotARepo: tt.symbol,
+					},
+				},
+			}
+
+			var sb strings.Builder
+			if err := f.FormatNotARepo(&sb); err != nil {
+				t.Fatalf("FormatNotARepo() error: %v", err)
+			}
+			compareStrings(t, tt.want, sb.String())
+		})
+	}
+}
+
+func TestFormatGitDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		bare    bool
+		repo    *fakeRepo
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "inside git dir",
+			repo: &fakeRepo{},
+			want: "StyleClear" + "StyleState" + "SymbolGitDir" + resetStyles,
+		},
+		{
+			name: "bare",
+			bare: true,
+			repo: &fakeRepo{
+				head: git.Head{Branch: "main", Hash: "4f2a1b0"},
+			},
+			want: "StyleClear" + "StyleState" + "SymbolBare" +
+				"StyleClear" + "StyleBranch" + "SymbolBranch" +
+				"StyleClear" + "StyleBranch" + "main" + resetStyles,
+		},
+		{
+			name: "bare detached",
+			bare: true,
+			repo: &fakeRepo{
+				head: git.Head{Hash: "4f2a1b0"},
+			},
+			want: "StyleClear" + "StyleState" + "SymbolBare" +
+				"StyleClear" + "StyleBranch" + "SymbolBranch" +
+				"StyleClear" + "StyleBranch" + "SymbolHashPrefix" + "4f2a1b0" + resetStyles,
+		},
+		{
+			name: "bare error",
+			bare: true,
+			repo: &fakeRepo{
+				err: errors.New("some error"),
+			},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -958,23 +2654,84 @@ func Test_stats(t *testing.T) {
 			f := &Formater{
 				Config: Config{
 					Styles: styles{
-						Clear:      "StyleClear",
-						Deletions:  "StyleDeletions",
-						Insertions: "StyleInsertions",
+						Clear:  "StyleClear",
+						State:  "StyleState",
+						Branch: "StyleBranch",
 					},
 					Symbols: symbols{
-						Deletions:  "SymbolDeletions",
-						Insertions: "SymbolInsertions",
+						Branch:     "SymbolBranch",
+						HashPrefix: "SymbolHashPrefix",
+						Bare:       "SymbolBare",
+						GitDir:     "SymbolGitDir",
 					},
-					Layout: []string{"stats"},
 				},
-				st: &gitstatus.Status{
-					Insertions: tt.insertions,
-					Deletions:  tt.deletions,
+				Repo: tt.repo,
+			}
+
+			var sb strings.Builder
+			err := f.FormatGitDir(&sb, tt.bare)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatGitDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			compareStrings(t, tt.want, sb.String())
+		})
+	}
+}
+
+func Test_repoFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *fakeRepo
+		want string
+	}{
+		{
+			name: "error",
+			repo: &fakeRepo{
+				repoFlags: git.RepoFlags{Shallow: true},
+				err:       errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name: "none",
+			repo: &fakeRepo{},
+			want: "",
+		},
+		{
+			name: "shallow",
+			repo: &fakeRepo{
+				repoFlags: git.RepoFlags{Shallow: true},
+			},
+			want: "StyleClear" + "StyleShallowSymbolShallow",
+		},
+		{
+			name: "all",
+			repo: &fakeRepo{
+				repoFlags: git.RepoFlags{Shallow: true, Partial: true, Sparse: true},
+			},
+			want: "StyleClear" + "StyleShallowSymbolShallow StylePartialSymbolPartial StyleSparseSymbolSparse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:   "StyleClear",
+						Shallow: "StyleShallow",
+						Partial: "StylePartial",
+						Sparse:  "StyleSparse",
+					},
+					Symbols: symbols{
+						Shallow: "SymbolShallow",
+						Partial: "SymbolPartial",
+						Sparse:  "SymbolSparse",
+					},
 				},
+				Repo: tt.repo,
 			}
 
-			compareStrings(t, tt.want, f.stats())
+			compareStrings(t, tt.want, f.repoFlags())
 		})
 	}
 }
@@ -1169,3 +2926,429 @@ func compareStrings(t *testing.T, want, got string) {
 %q`, got, want)
 	}
 }
+
+func Test_lfs(t *testing.T) {
+	allSymbols := symbols{
+		LFSPointers: "SymbolPointers",
+		LFSUnpushed: "SymbolUnpushed",
+		LFSLocks:    "SymbolLocks",
+	}
+
+	tests := []struct {
+		name    string
+		symbols symbols
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name:    "error",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				lfs: git.LFS{Pointers: 1},
+				err: errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name:    "nothing to show",
+			symbols: allSymbols,
+			repo:    &fakeRepo{},
+			want:    "",
+		},
+		{
+			name:    "all kinds",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				lfs: git.LFS{Pointers: 3, Unpushed: 2, Locks: 1},
+			},
+			want: "StyleClear" + "StylePointersSymbolPointers3" + "StyleUnpushedSymbolUnpushed2" + "StyleLocksSymbolLocks1",
+		},
+		{
+			name:    "locks only",
+			symbols: allSymbols,
+			repo: &fakeRepo{
+				lfs: git.LFS{Locks: 2},
+			},
+			want: "StyleClear" + "StyleLocksSymbolLocks2",
+		},
+		{
+			name: "empty symbol",
+			symbols: symbols{
+				LFSPointers: "SymbolPointers",
+				LFSUnpushed: "SymbolUnpushed",
+			},
+			repo: &fakeRepo{
+				lfs: git.LFS{Locks: 2},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:       "StyleClear",
+						LFSPointers: "StylePointers",
+						LFSUnpushed: "StyleUnpushed",
+						LFSLocks:    "StyleLocks",
+					},
+					Symbols: tt.symbols,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.lfs())
+		})
+	}
+}
+
+func Test_stashed(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	defer func(old func() time.Time) { timeNow = old }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	stashes := []git.Stash{
+		{Time: now.Add(-2 * time.Hour), Branch: "main"},
+		{Time: now.Add(-3 * 24 * time.Hour), Branch: "feat"},
+		{Time: now.Add(-20 * 24 * time.Hour), Branch: "main"},
+	}
+
+	tests := []struct {
+		name    string
+		options options
+		st      *gitstatus.Status
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name: "no options",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo: &fakeRepo{stashes: stashes},
+			want: "StyleStashSymbolStash3",
+		},
+		{
+			name:    "newest age",
+			options: options{StashAge: stashAgeNewest},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash3 2h",
+		},
+		{
+			name:    "oldest age",
+			options: options{StashAge: stashAgeOldest},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash3 20d",
+		},
+		{
+			name:    "branch count",
+			options: options{StashBranchCount: true},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash2/3",
+		},
+		{
+			name:    "branch count detached",
+			options: options{StashBranchCount: true},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{IsDetached: true}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash0/3",
+		},
+		{
+			name:    "above threshold",
+			options: options{StashAgeThreshold: 14 * 24 * time.Hour, StashAge: stashAgeNewest},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashOldSymbolStash3 2h",
+		},
+		{
+			name:    "below threshold",
+			options: options{StashAgeThreshold: 30 * 24 * time.Hour},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash3",
+		},
+		{
+			name:    "all options without count",
+			options: options{StashAge: stashAgeOldest, StashBranchCount: true, FlagsWithoutCount: true},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes},
+			want:    "StyleStashSymbolStash20d",
+		},
+		{
+			name:    "error",
+			options: options{StashAge: stashAgeNewest, StashBranchCount: true},
+			st:      &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, NumStashed: 3},
+			repo:    &fakeRepo{stashes: stashes, err: errors.New("some error")},
+			want:    "StyleStashSymbolStash3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Stashed:    "StyleStash",
+						StashedOld: "StyleStashOld",
+					},
+					Symbols: symbols{
+						Stashed: "SymbolStash",
+					},
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+				st:   tt.st,
+			}
+
+			compareStrings(t, tt.want, f.stashed())
+		})
+	}
+}
+
+func Test_identity(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbols symbols
+		options options
+		repo    *fakeRepo
+		want    string
+	}{
+		{
+			name:    "error",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			repo: &fakeRepo{
+				identity: git.Identity{Email: "me@work.com"},
+				err:      errors.New("some error"),
+			},
+			want: "",
+		},
+		{
+			name:    "not set",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			repo:    &fakeRepo{},
+			want:    "",
+		},
+		{
+			name:    "email",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			repo: &fakeRepo{
+				identity: git.Identity{Email: "me@work.com"},
+			},
+			want: "StyleClear" + "StyleIdentitySymbolIdentityme@work.com",
+		},
+		{
+			name:    "alias",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			options: options{IdentityAliases: map[string]string{"me@work.com": "work"}},
+			repo: &fakeRepo{
+				identity: git.Identity{Email: "me@work.com"},
+			},
+			want: "StyleClear" + "StyleIdentitySymbolIdentitywork",
+		},
+		{
+			name:    "no alias",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			options: options{IdentityAliases: map[string]string{"me@work.com": "work"}},
+			repo: &fakeRepo{
+				identity: git.Identity{Email: "me@home.org"},
+			},
+			want: "StyleClear" + "StyleIdentitySymbolIdentityme@home.org",
+		},
+		{
+			name:    "no signing key",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			repo: &fakeRepo{
+				identity: git.Identity{Email: "me@work.com", NoSigningKey: true},
+			},
+			want: "StyleClear" + "StyleIdentitySymbolIdentityme@work.com" + " " + "StyleNoKeySymbolNoKey",
+		},
+		{
+			name:    "no signing key without email",
+			symbols: symbols{Identity: "SymbolIdentity", NoSigningKey: "SymbolNoKey"},
+			repo: &fakeRepo{
+				identity: git.Identity{NoSigningKey: true},
+			},
+			want: "StyleClear" + "StyleNoKeySymbolNoKey",
+		},
+		{
+			name:    "empty no_signing_key symbol",
+			symbols: symbols{Identity: "SymbolIdentity"},
+			repo: &fakeRepo{
+				identity: git.Identity{NoSigningKey: true},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:        "StyleClear",
+						Identity:     "StyleIdentity",
+						NoSigningKey: "StyleNoKey",
+					},
+					Symbols: tt.symbols,
+					Options: tt.options,
+				},
+				Repo: tt.repo,
+			}
+
+			compareStrings(t, tt.want, f.identity())
+		})
+	}
+}
+
+func Test_branchPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		branch  string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			in:     "[]",
+			branch: "anything",
+			want:   true,
+		},
+		{
+			name:   "match",
+			in:     `['^(feat|fix|chore)/[A-Z]+-\d+']`,
+			branch: "feat/ABC-123",
+			want:   true,
+		},
+		{
+			name:   "no match",
+			in:     `['^(feat|fix|chore)/[A-Z]+-\d+']`,
+			branch: "my-branch",
+			want:   false,
+		},
+		{
+			name:   "second expression",
+			in:     `['^(feat|fix)/', '^renovate/']`,
+			branch: "renovate/gopkg.in-yaml.v3",
+			want:   true,
+		},
+		{
+			name:    "invalid expression",
+			in:      `['^feat/(']`,
+			wantErr: true,
+		},
+		{
+			name:    "not a list",
+			in:      `'^feat/'`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p branchPolicy
+			err := yaml.Unmarshal([]byte(tt.in), &p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := p.allows(tt.branch); got != tt.want {
+				t.Errorf("allows(%q) = %t, want %t", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchPolicy(t *testing.T) {
+	policy := branchPolicy{regexp.MustCompile(`^(feat|fix|chore)/[A-Z]+-\d+`)}
+	protected := []string{"main", "release/*"}
+
+	tests := []struct {
+		name string
+		st   *gitstatus.Status
+		want string
+	}{
+		{
+			name: "allowed",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "feat/ABC-123"}},
+			want: "StyleClear" + "StyleBranchSymbolBranch" + "StyleClear" + "StyleBranch" + "feat/ABC-123",
+		},
+		{
+			name: "violation",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "my-branch"}},
+			want: "StyleClear" + "StylePolicySymbolBranch" + "StyleClear" + "StylePolicy" + "my-branch" + "SymbolPolicy",
+		},
+		{
+			name: "protected",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main"}, IsClean: true},
+			want: "StyleClear" + "StyleProtectedSymbolBranch" + "StyleClear" + "StyleProtected" + "main",
+		},
+		{
+			name: "protected glob",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "release/1.2"}, IsClean: true},
+			want: "StyleClear" + "StyleProtectedSymbolBranch" + "StyleClear" + "StyleProtected" + "release/1.2",
+		},
+		{
+			name: "protected dirty",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main", NumModified: 1}},
+			want: "StyleClear" + "StyleAlertSymbolBranch" + "StyleClear" + "StyleAlert" + "main",
+		},
+		{
+			name: "protected ahead",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "release/1.2", AheadCount: 2}, IsClean: true},
+			want: "StyleClear" + "StyleAlertSymbolBranch" + "StyleClear" + "StyleAlert" + "release/1.2",
+		},
+		{
+			name: "protected behind",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "main", BehindCount: 2}, IsClean: true},
+			want: "StyleClear" + "StyleProtectedSymbolBranch" + "StyleClear" + "StyleProtected" + "main",
+		},
+		{
+			name: "dirty not protected",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "feat/ABC-123", AheadCount: 1, NumModified: 1}},
+			want: "StyleClear" + "StyleBranchSymbolBranch" + "StyleClear" + "StyleBranch" + "feat/ABC-123",
+		},
+		{
+			name: "glob doesn't match slashes",
+			st:   &gitstatus.Status{Porcelain: gitstatus.Porcelain{LocalBranch: "release/1.2/fix"}},
+			want: "StyleClear" + "StylePolicySymbolBranch" + "StyleClear" + "StylePolicy" + "release/1.2/fix" + "SymbolPolicy",
+		},
+		{
+			name: "detached",
+			st: &gitstatus.Status{
+				Porcelain: gitstatus.Porcelain{IsDetached: true},
+				HEAD:      "345e7a0",
+			},
+			want: "StyleClear" + "StyleBranchSymbolBranch" + "StyleClear" + "StyleBranch" + "SymbolHash" + "345e7a0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formater{
+				Config: Config{
+					Styles: styles{
+						Clear:           "StyleClear",
+						Branch:          "StyleBranch",
+						BranchPolicy:    "StylePolicy",
+						BranchProtected: "StyleProtected",
+
+						BranchProtectedAlert: "StyleAlert",
+					},
+					Symbols: symbols{
+						Branch:       "SymbolBranch",
+						HashPrefix:   "SymbolHash",
+						BranchPolicy: "SymbolPolicy",
+					},
+					Options: options{
+						BranchPolicy:      policy,
+						ProtectedBranches: protected,
+					},
+				},
+				st: tt.st,
+			}
+
+			compareStrings(t, tt.want, f.specialState())
+		})
+	}
+}